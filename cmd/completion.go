@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stenstromen/kubectl-mscale/pkg/scale"
+)
+
+// kubectlCompletionScript is the helper kubectl runs to complete "kubectl mscale ..."
+const kubectlCompletionScript = `#!/usr/bin/env sh
+# Completion helper for "kubectl mscale", install as kubectl_complete-mscale in your PATH
+kubectl-mscale __complete "$@"
+`
+
+// completionCmd replaces the default cobra completion command, adding a kubectl
+// plugin completion helper next to the shell scripts
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell|kubectl]",
+	Short: "Generate shell completion scripts",
+	Long: `Generate shell completion scripts for kubectl-mscale.
+
+When used as a kubectl plugin, kubectl (1.26+) completes "kubectl mscale ..." by
+running an executable named kubectl_complete-mscale from your PATH. The kubectl
+script is such a helper.`,
+	Example: `  # Complete "kubectl mscale ..." in any shell supported by kubectl
+  kubectl-mscale completion kubectl > /usr/local/bin/kubectl_complete-mscale
+  chmod +x /usr/local/bin/kubectl_complete-mscale
+
+  # Complete kubectl-mscale directly in bash
+  source <(kubectl-mscale completion bash)`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish", "powershell", "kubectl"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "bash":
+			return rootCmd.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			return rootCmd.GenFishCompletion(os.Stdout, true)
+		case "powershell":
+			return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+		case "kubectl":
+			_, err := fmt.Fprint(os.Stdout, kubectlCompletionScript)
+			return err
+		default:
+			return fmt.Errorf("unsupported shell: %s", args[0])
+		}
+	},
+}
+
+func init() {
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.AddCommand(completionCmd)
+}
+
+// completeNames completes the names of resources of a kind in the namespaces
+// given with -n, leaving out names already on the command line
+func completeNames(resourceType string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		scaler, err := newScalerForContext("", scale.Options{CurrentReplicas: -1})
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		namespaceList, err := scaler.ResolveNamespaces(cmd.Context(), scale.ParseNamespaces(namespaces))
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		refs, err := scale.ParseResourceRefs([]string{resourceType}, args)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var given []string
+		for _, ref := range refs {
+			if ref.Kind == resourceType {
+				given = append(given, ref.Name)
+			}
+		}
+
+		var names []string
+		for _, ns := range namespaceList {
+			list, err := scaler.List(cmd.Context(), resourceType, ns)
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+			names = append(names, list...)
+		}
+
+		return filterCompletions(names, given, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeNamespaces completes a comma-separated list of namespaces, one item at a time
+func completeNamespaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	scaler, err := newScalerForContext("", scale.Options{CurrentReplicas: -1})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	namespaceList, err := scaler.ListNamespaces(cmd.Context())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return completeList(namespaceList, toComplete)
+}
+
+// completeNamespace completes a single namespace
+func completeNamespace(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	scaler, err := newScalerForContext("", scale.Options{CurrentReplicas: -1})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	namespaceList, err := scaler.ListNamespaces(cmd.Context())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return filterCompletions(namespaceList, nil, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeContexts completes a comma-separated list of kubeconfig contexts, one item at a time
+func completeContexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	contextNames, err := kubeconfigContexts()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return completeList(contextNames, toComplete)
+}
+
+// completeContext completes a single kubeconfig context
+func completeContext(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	contextNames, err := kubeconfigContexts()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return filterCompletions(contextNames, nil, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeHPAModes completes the --hpa flag
+func completeHPAModes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var modes []string
+	for _, mode := range scale.HPAModes {
+		modes = append(modes, string(mode))
+	}
+	return filterCompletions(modes, nil, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeActiveJobsPolicies completes the --active-jobs flag
+func completeActiveJobsPolicies(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var policies []string
+	for _, policy := range scale.ActiveJobsPolicies {
+		policies = append(policies, string(policy))
+	}
+	return filterCompletions(policies, nil, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// kubeconfigContexts returns the context names in the kubeconfig, sorted
+func kubeconfigContexts() ([]string, error) {
+	config, err := configFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	contextNames := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		contextNames = append(contextNames, name)
+	}
+	sort.Strings(contextNames)
+	return contextNames, nil
+}
+
+// completeList completes the last item of a comma-separated list. The items
+// before it are kept as a prefix and left out of the candidates, and no space
+// is added so that another comma can follow.
+func completeList(candidates []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix, partial := "", toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix, partial = toComplete[:i+1], toComplete[i+1:]
+	}
+
+	var completions []string
+	for _, candidate := range filterCompletions(candidates, strings.Split(prefix, ","), partial) {
+		completions = append(completions, prefix+candidate)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// filterCompletions returns the candidates starting with toComplete that are
+// not already given, without duplicates
+func filterCompletions(candidates, given []string, toComplete string) []string {
+	seen := map[string]bool{}
+	for _, name := range given {
+		seen[name] = true
+	}
+
+	var completions []string
+	for _, candidate := range candidates {
+		if seen[candidate] || !strings.HasPrefix(candidate, toComplete) {
+			continue
+		}
+		seen[candidate] = true
+		completions = append(completions, candidate)
+	}
+	return completions
+}
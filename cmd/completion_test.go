@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCompleteList(t *testing.T) {
+	candidates := []string{"default", "staging", "production", "prod-eu"}
+
+	tests := []struct {
+		toComplete string
+		expected   []string
+	}{
+		{"", []string{"default", "staging", "production", "prod-eu"}},
+		{"prod", []string{"production", "prod-eu"}},
+		{"staging,", []string{"staging,default", "staging,production", "staging,prod-eu"}},
+		{"staging,production,p", []string{"staging,production,prod-eu"}},
+	}
+
+	for _, test := range tests {
+		completions, directive := completeList(candidates, test.toComplete)
+		if strings.Join(completions, " ") != strings.Join(test.expected, " ") {
+			t.Errorf("completeList(%q) = %v, expected %v", test.toComplete, completions, test.expected)
+		}
+		if directive&cobra.ShellCompDirectiveNoSpace == 0 {
+			t.Errorf("completeList(%q) should not add a space after the completion", test.toComplete)
+		}
+	}
+}
+
+func TestFilterCompletions(t *testing.T) {
+	completions := filterCompletions([]string{"api", "api", "web", "worker"}, []string{"web"}, "")
+	if strings.Join(completions, " ") != "api worker" {
+		t.Errorf("Expected [api worker], got %v", completions)
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stenstromen/kubectl-mscale/internal/controller"
+)
+
+var reconcileInterval time.Duration
+
+var controllerCmd = &cobra.Command{
+	Use:   "controller",
+	Short: "Run in-cluster, reconciling ScaleSchedule resources",
+	Long: `Run as a controller that reconciles ScaleSchedule resources (mscale.io/v1alpha1).
+A ScaleSchedule may reference a ScalePolicy with the options its targets are scaled with.
+
+Each ScaleSchedule is checked every --interval and scaled when its cron schedule is due.
+The last run, next run and per-target results are reported in the ScaleSchedule status.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clientset, dynamicClient, err := newClients(configFlags)
+		if err != nil {
+			return err
+		}
+
+		slog.Info("Reconciling scaleschedules", "interval", reconcileInterval)
+		return controller.New(clientset, dynamicClient).Run(cmd.Context(), reconcileInterval)
+	},
+}
+
+func init() {
+	controllerCmd.Flags().DurationVar(&reconcileInterval, "interval", 30*time.Second, "How often to reconcile ScaleSchedule resources")
+	rootCmd.AddCommand(controllerCmd)
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/stenstromen/kubectl-mscale/pkg/scale"
+)
+
+var (
+	fromNamespace string
+	toNamespace   string
+	fromContext   string
+	toContext     string
+	syncTarget    bool
+)
+
+// diffCmd compares replicas of all registered kinds, with one subcommand per kind
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare replicas between namespaces or clusters",
+	Long: `Compare the desired replicas of same-named resources between a source and a
+target namespace, optionally in different kubeconfig contexts. Mismatched replicas,
+resources missing from the target and extra resources in the target are reported.
+
+With --sync, mismatched resources in the target are scaled to the source replicas,
+and the command exits non-zero when any of them fails to scale. Without --sync,
+the command exits non-zero when differences are found.`,
+	Example: `  # Compare all kinds between production and staging
+  kubectl-mscale diff --from production --to staging
+
+  # Compare deployments between clusters and make the target match
+  kubectl-mscale diff deployment --from production --from-context prod --to-context staging --sync`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDiff(cmd.Context(), scale.Kinds(), nil)
+	},
+}
+
+func init() {
+	diffCmd.PersistentFlags().StringVar(&fromNamespace, "from", "", "Source namespace (defaults to the source context's namespace)")
+	diffCmd.PersistentFlags().StringVar(&toNamespace, "to", "", "Target namespace (defaults to the source namespace)")
+	diffCmd.PersistentFlags().StringVar(&fromContext, "from-context", "", "Source kubeconfig context (defaults to the current context)")
+	diffCmd.PersistentFlags().StringVar(&toContext, "to-context", "", "Target kubeconfig context (defaults to the current context)")
+	diffCmd.PersistentFlags().BoolVar(&syncTarget, "sync", false, "Scale mismatched resources in the target to the source replicas")
+	diffCmd.PersistentFlags().BoolVar(&forceConflicts, "force-conflicts", false, "Take ownership of the replica field in the target when syncing")
+	diffCmd.RegisterFlagCompletionFunc("from", completeNamespace)
+	diffCmd.RegisterFlagCompletionFunc("to", completeNamespace)
+	diffCmd.RegisterFlagCompletionFunc("from-context", completeContext)
+	diffCmd.RegisterFlagCompletionFunc("to-context", completeContext)
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+// createDiffCommand creates a diff subcommand for a registered kind
+func createDiffCommand(kind scale.KindScaler) {
+	kindCmd := &cobra.Command{
+		Use:     kind.Name(),
+		Aliases: kind.Aliases(),
+		Short:   fmt.Sprintf("Compare %s replicas between namespaces or clusters", kind.Name()),
+		// Complete resource names of this kind in the current namespace
+		ValidArgsFunction: completeNames(kind.Name()),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, err := scale.ParseResourceNames(kind.Name(), args)
+			if err != nil {
+				return err
+			}
+			return runDiff(cmd.Context(), []scale.KindScaler{kind}, names)
+		},
+	}
+
+	diffCmd.AddCommand(kindCmd)
+}
+
+// runDiff compares the kinds between the source and target and optionally syncs the target
+func runDiff(ctx context.Context, kinds []scale.KindScaler, names []string) error {
+	source, err := newScalerForContext(fromContext, scale.Options{CurrentReplicas: -1})
+	if err != nil {
+		return err
+	}
+
+	target, err := newScalerForContext(toContext, scale.Options{CurrentReplicas: -1, ForceConflicts: forceConflicts})
+	if err != nil {
+		return err
+	}
+
+	sourceNamespace := source.Namespaces(scale.ParseNamespaces(fromNamespace))[0]
+	targetNamespace := toNamespace
+	if targetNamespace == "" {
+		targetNamespace = sourceNamespace
+	}
+
+	if sourceNamespace == targetNamespace && fromContext == toContext {
+		return fmt.Errorf("source and target are the same, set --to or --to-context")
+	}
+
+	var sourceStatuses, targetStatuses []scale.WorkloadStatus
+	for _, kind := range kinds {
+		statuses, err := source.Status(ctx, kind.Name(), names, []string{sourceNamespace})
+		if err != nil {
+			return err
+		}
+		sourceStatuses = append(sourceStatuses, withoutWarnings(os.Stderr, statuses)...)
+
+		statuses, err = target.Status(ctx, kind.Name(), names, []string{targetNamespace})
+		if err != nil {
+			return err
+		}
+		targetStatuses = append(targetStatuses, withoutWarnings(os.Stderr, statuses)...)
+	}
+
+	differences := scale.Diff(sourceStatuses, targetStatuses)
+	if len(differences) == 0 {
+		fmt.Println("No differences found")
+		return nil
+	}
+
+	printDifferences(os.Stdout, differences, diffLabel(fromContext, sourceNamespace), diffLabel(toContext, targetNamespace))
+
+	if !syncTarget {
+		return fmt.Errorf("found %d differences", len(differences))
+	}
+
+	// Mismatched resources are scaled together, in the order of their ordering annotations
+	var resources []scale.Target
+	sourceReplicas := map[scale.Target]int{}
+	for _, difference := range differences {
+		if difference.Type != scale.DiffMismatch {
+			continue
+		}
+		resource := scale.Target{Kind: difference.Kind, Namespace: targetNamespace, Name: difference.Name}
+		resources = append(resources, resource)
+		sourceReplicas[resource] = difference.SourceReplicas
+	}
+
+	results, err := target.ScaleTargetsTo(ctx, resources, sourceReplicas)
+	fmt.Println()
+	printResults(results)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Failed() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to sync %d of %d resources", failed, len(results))
+	}
+	return nil
+}
+
+// diffLabel names one side of a comparison after its namespace and, if set, context
+func diffLabel(contextName, namespace string) string {
+	if contextName == "" {
+		return namespace
+	}
+	return contextName + "/" + namespace
+}
+
+// printDifferences prints one line per difference with the source and target replicas
+func printDifferences(w io.Writer, differences []scale.Difference, sourceLabel, targetLabel string) {
+	replicasText := func(replicas int) string {
+		if replicas < 0 {
+			return "-"
+		}
+		return fmt.Sprint(replicas)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(tw, "KIND\tNAME\t%s\t%s\tSTATUS\n", sourceLabel, targetLabel)
+	for _, difference := range differences {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", difference.Kind, difference.Name,
+			replicasText(difference.SourceReplicas), replicasText(difference.TargetReplicas), difference.Type)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/stenstromen/kubectl-mscale/pkg/scale"
+	"golang.org/x/term"
+)
+
+var (
+	allNamespaces bool
+	contexts      string
+	noColor       bool
+)
+
+// getCmd groups the read-only replica matrix commands, one per registered kind
+var getCmd = &cobra.Command{
+	Use:     "get",
+	Aliases: []string{"status"},
+	Short:   "Show a replica matrix of resources across namespaces or clusters",
+	Long: `Show the desired/ready replicas of resources as a matrix, with resources as rows
+and namespaces (or kubeconfig contexts) as columns. Rows where the desired replicas
+differ between columns, or a resource is missing from a column, are highlighted.`,
+	Example: `  # Compare all deployments across namespaces
+  kubectl-mscale get deployment -n default,staging,production
+
+  # Compare api deployments across all team namespaces
+  kubectl-mscale get deployment 'api-*' -n 'team-*'
+
+  # Compare a statefulset across clusters
+  kubectl-mscale get statefulset mysql -n production --contexts=prod-eu,prod-us`,
+}
+
+func init() {
+	rootCmd.AddCommand(getCmd)
+}
+
+// createGetCommand creates a get subcommand for a registered kind
+func createGetCommand(kind scale.KindScaler) {
+	resourceType := kind.Name()
+	kindCmd := &cobra.Command{
+		Use:     resourceType,
+		Aliases: kind.Aliases(),
+		Short:   fmt.Sprintf("Show %s replicas across multiple namespaces", resourceType),
+		// Complete resource names of this kind in the namespaces given with -n
+		ValidArgsFunction: completeNames(resourceType),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, err := scale.ParseResourceNames(resourceType, args)
+			if err != nil {
+				return err
+			}
+			if err := scale.ValidatePatterns(append(names, scale.ParseNamespaces(namespaces)...)); err != nil {
+				return err
+			}
+
+			columns, statuses, err := collectStatuses(cmd.Context(), resourceType, names)
+			if err != nil {
+				return err
+			}
+
+			printMatrix(os.Stdout, columns, statuses, useColor())
+			return nil
+		},
+	}
+
+	kindCmd.Flags().StringVarP(&namespaces, "namespace", "n", "", "Comma-separated list of namespaces or glob patterns (defaults to the current context's namespace)")
+	kindCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Show resources in all namespaces")
+	kindCmd.Flags().StringVar(&contexts, "contexts", "", "Comma-separated list of kubeconfig contexts to compare (defaults to the current context)")
+	kindCmd.Flags().StringVar(&nameRegex, "name-regex", "", "Only show resources whose names match this regular expression")
+	kindCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable highlighting")
+	kindCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	kindCmd.RegisterFlagCompletionFunc("contexts", completeContexts)
+
+	getCmd.AddCommand(kindCmd)
+}
+
+// matrixCell is the replica status of a resource in one matrix column
+type matrixCell struct {
+	column int
+	status scale.ReplicaStatus
+}
+
+// collectStatuses gathers the statuses of a resource type for every context and
+// namespace, returning the column labels and the cells keyed by kind/name
+func collectStatuses(ctx context.Context, resourceType string, names []string) ([]string, map[string][]matrixCell, error) {
+	contextList := strings.Split(contexts, ",")
+
+	var columns []string
+	statuses := map[string][]matrixCell{}
+	for _, contextName := range contextList {
+		opts := scale.Options{CurrentReplicas: -1, NameRegex: nameRegex}
+		if err := opts.Validate(); err != nil {
+			return nil, nil, err
+		}
+
+		scaler, err := newScalerForContext(contextName, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		namespaceList, err := scaler.ResolveNamespaces(ctx, scale.ParseNamespaces(namespaces))
+		if err != nil {
+			return nil, nil, err
+		}
+		if allNamespaces {
+			namespaceList, err = scaler.ListNamespaces(ctx)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		for _, ns := range namespaceList {
+			workloads, err := scaler.Status(ctx, resourceType, names, []string{ns})
+			if err != nil {
+				return nil, nil, err
+			}
+
+			column := len(columns)
+			columns = append(columns, columnLabel(contextName, ns, len(contextList) > 1, len(namespaceList) > 1))
+			for _, workload := range withoutWarnings(os.Stderr, workloads) {
+				row := workload.Kind + "/" + workload.Name
+				statuses[row] = append(statuses[row], matrixCell{column: column, status: workload.ReplicaStatus})
+			}
+		}
+	}
+
+	return columns, statuses, nil
+}
+
+// withoutWarnings prints the warnings of statuses that could not be read and
+// returns the others
+func withoutWarnings(w io.Writer, statuses []scale.WorkloadStatus) []scale.WorkloadStatus {
+	var read []scale.WorkloadStatus
+	for _, status := range statuses {
+		if status.Warning != "" {
+			fmt.Fprintf(w, "Warning: %s %s in namespace %s: %s\n", status.Kind, status.Name, status.Namespace, status.Warning)
+			continue
+		}
+		read = append(read, status)
+	}
+	return read
+}
+
+// columnLabel names a matrix column after its context, namespace or both
+func columnLabel(contextName, namespace string, multipleContexts, multipleNamespaces bool) string {
+	switch {
+	case multipleContexts && multipleNamespaces:
+		return contextName + "/" + namespace
+	case multipleContexts:
+		return contextName
+	default:
+		return namespace
+	}
+}
+
+// ANSI colours used for highlighting. All codes have the same length, so that
+// every cell of a column grows by the same amount and tabwriter stays aligned.
+const (
+	colorDefault = "\x1b[39m"
+	colorRed     = "\x1b[31m"
+	colorYellow  = "\x1b[33m"
+	colorReset   = "\x1b[0m"
+)
+
+// useColor reports whether highlighting should use colours
+func useColor() bool {
+	return !noColor && os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// printMatrix prints the replica matrix as desired/ready per column. Rows whose
+// desired replicas differ between columns are marked in the DRIFT column and
+// highlighted in yellow, and cells with fewer ready than desired replicas in red.
+func printMatrix(w io.Writer, columns []string, statuses map[string][]matrixCell, color bool) {
+	if len(statuses) == 0 {
+		fmt.Fprintln(w, "No resources found")
+		return
+	}
+
+	paint := func(text, code string) string {
+		if !color {
+			return text
+		}
+		return code + text + colorReset
+	}
+
+	rows := make([]string, 0, len(statuses))
+	for row := range statuses {
+		rows = append(rows, row)
+	}
+	sort.Strings(rows)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	header := []string{paint("NAME", colorDefault)}
+	for _, column := range columns {
+		header = append(header, paint(strings.ToUpper(column), colorDefault))
+	}
+	header = append(header, paint("DRIFT", colorDefault))
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, row := range rows {
+		cells := make([]*scale.ReplicaStatus, len(columns))
+		for _, cell := range statuses[row] {
+			status := cell.status
+			cells[cell.column] = &status
+		}
+
+		drift := false
+		for _, cell := range cells {
+			if cell == nil || cells[0] == nil || cell.Desired != cells[0].Desired {
+				drift = true
+				break
+			}
+		}
+
+		line := []string{paint(row, colorDefault)}
+		for _, cell := range cells {
+			switch {
+			case cell == nil:
+				line = append(line, paint("-", colorYellow))
+			case drift:
+				line = append(line, paint(fmt.Sprintf("%d/%d", cell.Desired, cell.Ready), colorYellow))
+			case cell.Ready < cell.Desired:
+				line = append(line, paint(fmt.Sprintf("%d/%d", cell.Desired, cell.Ready), colorRed))
+			default:
+				line = append(line, paint(fmt.Sprintf("%d/%d", cell.Desired, cell.Ready), colorDefault))
+			}
+		}
+
+		if drift {
+			line = append(line, paint("*", colorYellow))
+		} else {
+			line = append(line, paint("", colorDefault))
+		}
+		fmt.Fprintln(tw, strings.Join(line, "\t"))
+	}
+
+	tw.Flush()
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stenstromen/kubectl-mscale/pkg/scale"
+)
+
+func TestPrintMatrix(t *testing.T) {
+	columns := []string{"staging", "production"}
+	statuses := map[string][]matrixCell{
+		"deployment/api": {
+			{column: 0, status: scale.ReplicaStatus{Name: "api", Desired: 2, Ready: 2}},
+			{column: 1, status: scale.ReplicaStatus{Name: "api", Desired: 2, Ready: 1}},
+		},
+		"deployment/web": {
+			{column: 1, status: scale.ReplicaStatus{Name: "web", Desired: 3, Ready: 3}},
+		},
+	}
+
+	var out bytes.Buffer
+	printMatrix(&out, columns, statuses, false)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d:\n%s", len(lines), out.String())
+	}
+
+	expected := [][]string{
+		{"NAME", "STAGING", "PRODUCTION", "DRIFT"},
+		{"deployment/api", "2/2", "2/1"},
+		{"deployment/web", "-", "3/3", "*"},
+	}
+	for i, fields := range expected {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(fields, " ") {
+			t.Errorf("Expected line %d to be %v, got %v", i, fields, got)
+		}
+	}
+}
+
+func TestColumnLabel(t *testing.T) {
+	tests := []struct {
+		multipleContexts, multipleNamespaces bool
+		expected                             string
+	}{
+		{false, false, "staging"},
+		{false, true, "staging"},
+		{true, false, "prod-eu"},
+		{true, true, "prod-eu/staging"},
+	}
+
+	for _, test := range tests {
+		if got := columnLabel("prod-eu", "staging", test.multipleContexts, test.multipleNamespaces); got != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, got)
+		}
+	}
+}
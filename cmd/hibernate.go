@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/stenstromen/kubectl-mscale/pkg/scale"
+)
+
+// hibernateCmd scales every scalable resource in namespaces to zero
+var hibernateCmd = &cobra.Command{
+	Use:   "hibernate <namespace>...",
+	Short: "Scale every resource in namespaces to zero, recording their replicas",
+	Long: `Scale every Deployment and StatefulSet in the namespaces to zero, suspend their
+CronJobs and pause their KEDA ScaledObjects, and pin their HorizontalPodAutoscalers
+at their min replicas. The previous replicas are recorded on each namespace, so
+that wake can restore them. Only resources hibernated successfully are recorded,
+so running it again retries the others. Namespaces with nothing left to hibernate
+are skipped.`,
+	Example: `  # Shut down preview namespaces
+  kubectl-mscale hibernate preview-123 'preview-*'`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeNamespaces,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scaler, err := newScaler()
+		if err != nil {
+			return err
+		}
+		if err := scale.ValidatePatterns(args); err != nil {
+			return err
+		}
+
+		results, err := scaler.Hibernate(cmd.Context(), args)
+		printResults(results)
+		return err
+	},
+}
+
+// wakeCmd restores namespaces hibernated by hibernateCmd
+var wakeCmd = &cobra.Command{
+	Use:   "wake <namespace>...",
+	Short: "Restore the resources in hibernated namespaces",
+	Long: `Restore the bounds of HorizontalPodAutoscalers pinned by hibernate, then the
+resources in hibernated namespaces to their recorded replicas, in the order given
+by their mscale.io/order and mscale.io/depends-on annotations, and hand paused
+KEDA ScaledObjects back to KEDA. The record is removed from each namespace once
+all of its resources are restored.`,
+	Example: `  # Start preview namespaces again and wait for them to become ready
+  kubectl-mscale wake preview-123 --wait`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeNamespaces,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scaler, err := newScaler()
+		if err != nil {
+			return err
+		}
+		if err := scale.ValidatePatterns(args); err != nil {
+			return err
+		}
+
+		results, err := scaler.Wake(cmd.Context(), args)
+		printResults(results)
+		if err != nil {
+			return err
+		}
+		return printPlan(cmd.Context(), scaler, results)
+	},
+}
+
+func init() {
+	for _, namespaceCmd := range []*cobra.Command{hibernateCmd, wakeCmd} {
+		namespaceCmd.Flags().StringVar(&excludeNames, "exclude", "", "Comma-separated list of resource names or glob patterns to leave alone, e.g. *-canary")
+		namespaceCmd.Flags().StringVar(&nameRegex, "name-regex", "", "Only scale resources whose names match this regular expression, e.g. ^worker-")
+		namespaceCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Run all checks and print what would be scaled without changing anything")
+		namespaceCmd.Flags().BoolVar(&forceConflicts, "force-conflicts", false, "Take ownership of the replica field when another field manager, such as a GitOps controller, applied it")
+		namespaceCmd.Flags().DurationVar(&waitTimeout, "wait-timeout", scale.DefaultWaitTimeout, "How long to wait for each level of ordered resources, or with --wait for all resources, to become ready")
+		namespaceCmd.Flags().BoolVar(&wait, "wait", false, "Wait for the scaled resources to become ready")
+		namespaceCmd.Flags().BoolVar(&skipGitOps, "skip-gitops", false, "Leave resources managed by Argo CD or Flux alone instead of printing a warning")
+		namespaceCmd.Flags().StringVar(&argoCDNamespace, "argocd-namespace", scale.DefaultArgoCDNamespace, "Namespace of the Argo CD Applications")
+		namespaceCmd.Flags().StringVar(&activeJobs, "active-jobs", string(scale.ActiveJobsKeep), "What to do with running Jobs of CronJobs when hibernating: keep, delete or suspend (resumed again on wake)")
+		namespaceCmd.RegisterFlagCompletionFunc("active-jobs", completeActiveJobsPolicies)
+		rootCmd.AddCommand(namespaceCmd)
+	}
+
+	hibernateCmd.Flags().BoolVar(&ignorePDB, "ignore-pdb", false, "Scale down even when a PodDisruptionBudget would be violated, printing a warning")
+	hibernateCmd.Flags().BoolVar(&adjustPDB, "adjust-pdb", false, "Relax violated PodDisruptionBudgets and restore them on wake")
+	hibernateCmd.MarkFlagsMutuallyExclusive("ignore-pdb", "adjust-pdb")
+	wakeCmd.Flags().BoolVar(&ignoreQuota, "ignore-quota", false, "Scale up even when a ResourceQuota would be exceeded, printing a warning")
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/stenstromen/kubectl-mscale/pkg/scale"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// printPlan prints the cluster capacity estimate for the scale-ups of a dry run
+func printPlan(ctx context.Context, scaler *scale.Scaler, results []scale.Result) error {
+	if !dryRun {
+		return nil
+	}
+
+	scaleUp := false
+	for _, result := range results {
+		scaleUp = scaleUp || (result.Succeeded() && result.PreviousReplicas >= 0 && result.Replicas > result.PreviousReplicas)
+	}
+	if !scaleUp {
+		return nil
+	}
+
+	estimate, err := scaler.EstimateCapacity(ctx, results)
+	if err != nil {
+		return fmt.Errorf("error estimating capacity: %v", err)
+	}
+
+	fmt.Println()
+	printCapacity(os.Stdout, estimate)
+	return nil
+}
+
+// printCapacity prints the free and added capacity per node pool, followed by
+// the pods that fit on no node
+func printCapacity(w io.Writer, estimate scale.CapacityEstimate) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NODE POOL\tNODES\tFREE CPU\tFREE MEMORY\tFREE PODS\tNEW PODS\tNEW CPU\tNEW MEMORY")
+	for _, pool := range estimate.Pools {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%d\t%d\t%s\t%s\n", pool.Name, pool.Nodes,
+			formatCPU(pool.FreeCPU), formatMemory(pool.FreeMemory), pool.FreePods,
+			pool.AddedPods, formatCPU(pool.AddedCPU), formatMemory(pool.AddedMemory))
+	}
+	tw.Flush()
+
+	if estimate.Fits() {
+		fmt.Fprintln(w, "\nAll new pods are estimated to fit")
+		return
+	}
+
+	fmt.Fprintln(w)
+	for _, pods := range estimate.Unschedulable {
+		fmt.Fprintf(w, "Warning: %d pods of %s %s in namespace %s are estimated not to fit on any node\n",
+			pods.Pods, pods.Kind, pods.Name, pods.Namespace)
+	}
+}
+
+// formatCPU formats a CPU quantity in cores with one decimal
+func formatCPU(quantity resource.Quantity) string {
+	return fmt.Sprintf("%.1f", float64(quantity.MilliValue())/1000)
+}
+
+// formatMemory formats a memory quantity in GiB with one decimal
+func formatMemory(quantity resource.Quantity) string {
+	return fmt.Sprintf("%.1fGi", float64(quantity.Value())/(1<<30))
+}
@@ -1,11 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/stenstromen/kubectl-mscale/internal/scale"
+	"github.com/stenstromen/kubectl-mscale/pkg/scale"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 )
 
 var (
@@ -14,11 +22,39 @@ var (
 	filename        string
 	currentReplicas int
 	all             bool
+
+	excludeNamespaces string
+	excludeNames      string
+	nameRegex         string
+	ignorePDB         bool
+	adjustPDB         bool
+	ignoreQuota       bool
+	dryRun            bool
+	forceConflicts    bool
+	waitTimeout       time.Duration
+	wait              bool
+	skipGitOps        bool
+	suspendGitOps     bool
+	resumeGitOps      bool
+	argoCDNamespace   string
+	hpaMode           string
+	minBound          string
+	maxBound          string
+	preserveRatio     bool
+	restoreBounds     bool
+	resume            bool
+	suspend           bool
+	activeJobs        string
+	completions       bool
+	resolveOwner      bool
+
+	// configFlags holds the standard kubectl connection flags (--kubeconfig, --context, --as, ...)
+	configFlags = genericclioptions.NewConfigFlags(true)
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "kubectl-mscale",
+	Use:   "kubectl-mscale [kind[,kind...]] [name|kind/name...]",
 	Short: "Scale resources across multiple namespaces",
 	Long:  `A kubectl plugin for scaling resources across multiple namespaces.`,
 	Example: `  # Scale all deployments to 3 replicas across multiple namespaces
@@ -29,55 +65,431 @@ var rootCmd = &cobra.Command{
   
   # Scale ALL deployments to 0 replicas across all namespaces
   kubectl-mscale deployment --replicas=0 --all
+
+  # Scale deployments starting with api- in every team namespace
+  kubectl-mscale deployment 'api-*' --replicas=2 -n 'team-*'
+
+  # Scale deployments whose names match a regular expression
+  kubectl-mscale deployment --replicas=0 --name-regex '^worker-'
   
+  # Show what would be scaled and whether the cluster can fit it
+  kubectl-mscale deployment --replicas=10 -n staging,production --dry-run
+
+  # Scale deployments and statefulsets together
+  kubectl-mscale deploy,sts --replicas=0 -n staging,dev
+
+  # Scale resources of different kinds by name
+  kubectl-mscale deployment/api statefulset/db --replicas=1 -n staging
+
   # Scale resources defined in a YAML file
   kubectl-mscale statefulset --filename=statefulset.yaml --replicas=3`,
+	// Kinds without a command of their own, such as deploy,sts or the kind of a
+	// leading kind/name argument, are scaled by the root command
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Help()
+		}
+
+		kinds, args, err := parseKindArgs(args)
+		if err != nil {
+			return err
+		}
+		return runScale(cmd, kinds, args)
+	},
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	addCommands()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Println(err)
+		stop()
 		os.Exit(1)
 	}
 }
 
+// addCommands adds the commands of the registered kinds, and the scale flags of
+// all of them to the root command. Commands are generated at execution time so
+// kinds registered by other packages during init are included.
+func addCommands() {
+	kinds := scale.Kinds()
+	for _, kind := range kinds {
+		createScaleCommand(kind)
+		createGetCommand(kind)
+		createDiffCommand(kind)
+	}
+	addScaleFlags(rootCmd, kinds)
+}
+
+// parseKindArgs parses the kinds in the first argument to the root command,
+// either a comma-separated list such as deploy,sts, returned without the
+// argument, or the kind of a kind/name argument, returned with it
+func parseKindArgs(args []string) ([]string, []string, error) {
+	if kindName, _, found := strings.Cut(args[0], "/"); found {
+		kind, err := scale.Lookup(kindName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid resource %s: %v", args[0], err)
+		}
+		return []string{kind.Name()}, args, nil
+	}
+
+	var kinds []string
+	for _, kindName := range strings.Split(args[0], ",") {
+		kind, err := scale.Lookup(kindName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unknown command or kind %q: %v", args[0], err)
+		}
+		kinds = append(kinds, kind.Name())
+	}
+	return kinds, args[1:], nil
+}
+
 func init() {
-	createScaleCommand("deployment", "deployment", "deploy", "deployments")
-	createScaleCommand("statefulset", "statefulset", "sts", "statefulsets")
-	createScaleCommand("replicaset", "replicaset", "rs", "replicasets")
-	createScaleCommand("replicationcontroller", "replicationcontroller", "rc", "replicationcontrollers")
-	createScaleCommand("job", "job", "jobs")
-	createScaleCommand("cronjob", "cronjob", "cj", "cronjobs")
-	createScaleCommand("horizontalpodautoscaler", "horizontalpodautoscaler", "hpa", "horizontalpodautoscalers")
+	// -n is a comma-separated list on the scale commands, so leave it out of the shared flags
+	configFlags.Namespace = nil
+	configFlags.AddFlags(rootCmd.PersistentFlags())
+	rootCmd.RegisterFlagCompletionFunc("context", completeContext)
 }
 
-// createScaleCommand creates a new scale command with the given name and aliases
-func createScaleCommand(use string, resourceType string, aliases ...string) {
+// createScaleCommand creates a new scale command for a registered kind
+func createScaleCommand(kind scale.KindScaler) {
+	resourceType := kind.Name()
 	scaleCmd := &cobra.Command{
-		Use:     use,
-		Aliases: aliases,
-		Short:   fmt.Sprintf("Scale %s across multiple namespaces", use),
+		Use:     resourceType,
+		Aliases: kind.Aliases(),
+		Short:   fmt.Sprintf("Scale %s across multiple namespaces", resourceType),
 		Args:    cobra.MinimumNArgs(0),
+		// Complete resource names of this kind in the namespaces given with -n
+		ValidArgsFunction: completeNames(resourceType),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if filename != "" {
-				return scale.ScaleFromFile(filename, replicas, currentReplicas)
-			}
+			return runScale(cmd, []string{resourceType}, args)
+		},
+	}
+	addScaleFlags(scaleCmd, []scale.KindScaler{kind})
 
-			// If --all flag is set or no args are provided, scale all resources of this type
-			if all || len(args) == 0 {
-				return scale.ScaleAllResources(resourceType, namespaces, replicas, currentReplicas)
-			}
+	rootCmd.AddCommand(scaleCmd)
+}
 
-			return scale.ScaleFromArgs(args, resourceType, namespaces, replicas, currentReplicas)
-		},
+// addScaleFlags adds the scale flags to a command scaling the kinds, including
+// the flags specific to any of them
+func addScaleFlags(scaleCmd *cobra.Command, kinds []scale.KindScaler) {
+	var bounds, resumable, suspendable, elastic, jobOwner bool
+	for _, kind := range kinds {
+		_, ok := kind.(scale.BoundsKind)
+		bounds = bounds || ok
+		_, ok = kind.(scale.ResumableKind)
+		resumable = resumable || ok
+		_, ok = kind.(scale.SuspendableKind)
+		suspendable = suspendable || ok
+		_, ok = kind.(scale.ElasticKind)
+		elastic = elastic || ok
+		_, ok = kind.(scale.JobOwnerKind)
+		jobOwner = jobOwner || ok
 	}
 
 	scaleCmd.Flags().IntVar(&replicas, "replicas", 0, "Number of replicas")
-	scaleCmd.Flags().StringVarP(&namespaces, "namespace", "n", "", "Comma-separated list of namespaces")
+	scaleCmd.Flags().StringVarP(&namespaces, "namespace", "n", "", "Comma-separated list of namespaces or glob patterns, e.g. team-* (defaults to the current context's namespace)")
 	scaleCmd.Flags().StringVarP(&filename, "filename", "f", "", "Filename, directory, or URL to files to use to scale the resource")
 	scaleCmd.Flags().IntVar(&currentReplicas, "current-replicas", -1, "Precondition for current size. Requires that the current size of the resource match this value in order to scale")
 	scaleCmd.Flags().BoolVar(&all, "all", false, "Scale all resources of the specified type in the given namespaces")
-	scaleCmd.MarkFlagRequired("replicas")
+	scaleCmd.Flags().StringVar(&excludeNamespaces, "exclude-namespace", "", "Comma-separated list of namespaces or glob patterns to leave alone, e.g. kube-*")
+	scaleCmd.Flags().StringVar(&excludeNames, "exclude", "", "Comma-separated list of resource names or glob patterns to leave alone, e.g. *-canary")
+	scaleCmd.Flags().StringVar(&nameRegex, "name-regex", "", "Only scale resources whose names match this regular expression, e.g. ^worker-")
+	scaleCmd.Flags().BoolVar(&ignorePDB, "ignore-pdb", false, "Scale down even when a PodDisruptionBudget would be violated, printing a warning")
+	scaleCmd.Flags().BoolVar(&adjustPDB, "adjust-pdb", false, "Relax violated PodDisruptionBudgets when scaling to zero and restore them when scaled up again")
+	scaleCmd.MarkFlagsMutuallyExclusive("ignore-pdb", "adjust-pdb")
+	scaleCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Run all checks and print what would be scaled, with a cluster capacity estimate for scale-ups, without changing anything")
+	scaleCmd.Flags().BoolVar(&forceConflicts, "force-conflicts", false, "Take ownership of the replica field when another field manager, such as a GitOps controller, applied it")
+	scaleCmd.Flags().DurationVar(&waitTimeout, "wait-timeout", scale.DefaultWaitTimeout, "How long to wait for each level of ordered resources, or with --wait for all resources, to become ready")
+	scaleCmd.Flags().BoolVar(&wait, "wait", false, "Wait for the scaled resources to become ready")
+	scaleCmd.Flags().BoolVar(&ignoreQuota, "ignore-quota", false, "Scale up even when a ResourceQuota would be exceeded, printing a warning")
+	scaleCmd.Flags().BoolVar(&skipGitOps, "skip-gitops", false, "Leave resources managed by Argo CD or Flux alone instead of printing a warning")
+	scaleCmd.Flags().BoolVar(&suspendGitOps, "suspend-gitops", false, "Suspend the Flux Kustomization or HelmRelease, or disable Argo CD automated sync, of managed resources before scaling")
+	scaleCmd.Flags().BoolVar(&resumeGitOps, "resume-gitops", false, "Resume GitOps reconciliation suspended by --suspend-gitops after scaling")
+	scaleCmd.MarkFlagsMutuallyExclusive("skip-gitops", "suspend-gitops", "resume-gitops")
+	scaleCmd.Flags().StringVar(&argoCDNamespace, "argocd-namespace", scale.DefaultArgoCDNamespace, "Namespace of the Argo CD Applications")
+	scaleCmd.Flags().BoolVar(&resolveOwner, "resolve-owner", false, "Scale the controller owning a resource, such as the Deployment of a ReplicaSet, instead of skipping the resource")
+	scaleCmd.Flags().StringVar(&hpaMode, "hpa", string(scale.HPAWarn), "How to scale resources targeted by a HorizontalPodAutoscaler: warn, skip, adjust (set its minReplicas), pin (set its min and max replicas) or unpin (restore the recorded bounds)")
+	if bounds {
+		scaleCmd.Flags().StringVar(&minBound, "min", "", "Min replicas, or a change to them such as +2 or -1")
+		scaleCmd.Flags().StringVar(&maxBound, "max", "", "Max replicas, or a change to them such as +2 or -1")
+		scaleCmd.Flags().BoolVar(&preserveRatio, "preserve-ratio", true, "Scale a bound that is not given by the same ratio as the other")
+		scaleCmd.Flags().BoolVar(&restoreBounds, "restore-bounds", false, "Restore the min and max replicas recorded before they were first changed")
+		scaleCmd.MarkFlagsMutuallyExclusive("replicas", "min")
+		scaleCmd.MarkFlagsMutuallyExclusive("replicas", "restore-bounds")
+		scaleCmd.MarkFlagsMutuallyExclusive("min", "restore-bounds")
+		scaleCmd.MarkFlagsMutuallyExclusive("max", "restore-bounds")
+	}
+	if resumable {
+		scaleCmd.Flags().BoolVar(&resume, "resume", false, "Hand the resources back to their autoscaler instead of scaling them")
+		scaleCmd.MarkFlagsMutuallyExclusive("replicas", "resume")
+	}
+	if suspendable {
+		scaleCmd.Flags().BoolVar(&suspend, "suspend", false, "Suspend the resources instead of scaling them, undone with --resume")
+		scaleCmd.MarkFlagsMutuallyExclusive("replicas", "suspend", "resume")
+	}
+	if elastic {
+		scaleCmd.Flags().BoolVar(&completions, "completions", false, "Scale the completions of Indexed Jobs to the replicas along with their parallelism")
+		scaleCmd.MarkFlagsMutuallyExclusive("completions", "suspend")
+		scaleCmd.MarkFlagsMutuallyExclusive("completions", "resume")
+	}
+	if jobOwner {
+		scaleCmd.Flags().StringVar(&activeJobs, "active-jobs", string(scale.ActiveJobsKeep), "What to do with running Jobs when suspending or scaling to 0: keep, delete or suspend (resumed along with the CronJob by --resume or scaling up)")
+		scaleCmd.RegisterFlagCompletionFunc("active-jobs", completeActiveJobsPolicies)
+	}
+	scaleCmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
+	scaleCmd.RegisterFlagCompletionFunc("exclude-namespace", completeNamespaces)
+	scaleCmd.RegisterFlagCompletionFunc("hpa", completeHPAModes)
+}
 
-	rootCmd.AddCommand(scaleCmd)
+// runScale scales resources of the kinds given by the scale flags, and resources
+// given as name or kind/name arguments
+func runScale(cmd *cobra.Command, kinds []string, args []string) error {
+	// Kinds with bounds can be scaled by --min, --max or --restore-bounds alone,
+	// and resumable kinds resumed or suspended without replicas
+	if !cmd.Flags().Changed("replicas") {
+		if minBound == "" && maxBound == "" && !restoreBounds && !resume && !suspend {
+			return fmt.Errorf(`required flag(s) "replicas" not set`)
+		}
+		replicas = -1
+	}
+
+	scaler, err := newScaler()
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	namespaceList := scale.ParseNamespaces(namespaces)
+	if err := scale.ValidatePatterns(namespaceList); err != nil {
+		return err
+	}
+
+	var results []scale.Result
+	switch {
+	case filename != "":
+		file, err := os.Open(filename)
+		if err != nil {
+			return fmt.Errorf("error opening file: %v", err)
+		}
+		defer file.Close()
+
+		results, err = scaler.ScaleFile(ctx, file)
+		printResults(results)
+		if err != nil {
+			return err
+		}
+		return printPlan(ctx, scaler, results)
+
+	// If --all flag is set or no args are provided, scale all resources of the kinds
+	case all || len(args) == 0:
+		results, err = scaler.ScaleAllKinds(ctx, kinds, namespaceList)
+
+	default:
+		if nameRegex != "" {
+			return fmt.Errorf("--name-regex cannot be combined with resource names")
+		}
+
+		var refs []scale.ResourceRef
+		refs, err = scale.ParseResourceRefs(kinds, args)
+		if err != nil {
+			return err
+		}
+		var names []string
+		for _, ref := range refs {
+			names = append(names, ref.Name)
+		}
+		if err := scale.ValidatePatterns(names); err != nil {
+			return err
+		}
+		results, err = scaler.ScaleRefs(ctx, refs, namespaceList)
+	}
+
+	printResults(results)
+	if err != nil {
+		return err
+	}
+	return printPlan(ctx, scaler, results)
+}
+
+// newScaler creates a Scaler from the command line flags
+func newScaler() (*scale.Scaler, error) {
+	opts := scale.Options{
+		Replicas:          replicas,
+		CurrentReplicas:   currentReplicas,
+		ExcludeNamespaces: splitList(excludeNamespaces),
+		ExcludeNames:      splitList(excludeNames),
+		NameRegex:         nameRegex,
+		IgnorePDB:         ignorePDB,
+		AdjustPDB:         adjustPDB,
+		IgnoreQuota:       ignoreQuota,
+		DryRun:            dryRun,
+		ForceConflicts:    forceConflicts,
+		WaitTimeout:       waitTimeout,
+		Wait:              wait,
+		SkipGitOps:        skipGitOps,
+		SuspendGitOps:     suspendGitOps,
+		ResumeGitOps:      resumeGitOps,
+		ArgoCDNamespace:   argoCDNamespace,
+		HPAMode:           scale.HPAMode(hpaMode),
+		MinReplicas:       minBound,
+		MaxReplicas:       maxBound,
+		IndependentBounds: !preserveRatio,
+		RestoreBounds:     restoreBounds,
+		Resume:            resume,
+		Suspend:           suspend,
+		Completions:       completions,
+		ResolveOwner:      resolveOwner,
+		ActiveJobs:        scale.ActiveJobsPolicy(activeJobs),
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return newScalerForContext("", opts)
+}
+
+// newScalerForContext creates a Scaler for a kubeconfig context, or the current
+// context when empty, defaulting to that context's namespace
+func newScalerForContext(contextName string, opts scale.Options) (*scale.Scaler, error) {
+	flags := flagsForContext(contextName)
+
+	clientset, dynamicClient, err := newClients(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.DefaultNamespace, err = currentNamespace(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	return scale.NewScaler(clientset, dynamicClient, opts), nil
+}
+
+// splitList splits a comma-separated flag value, returning nil when empty
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// printResults prints the outcome of each resource, followed by a summary
+func printResults(results []scale.Result) {
+	if len(results) == 0 {
+		fmt.Println("No resources found")
+		return
+	}
+
+	scaled, failed, skipped := 0, 0, 0
+	for _, result := range results {
+		for _, warning := range result.Warnings {
+			fmt.Printf("Warning: %s %s in namespace %s: %s\n", result.Kind, result.Name, result.Namespace, warning)
+		}
+
+		switch {
+		case result.Skipped != "" && result.Name == "":
+			skipped++
+			fmt.Printf("Skipped namespace %s: %s\n", result.Namespace, result.Skipped)
+		case result.Skipped != "":
+			skipped++
+			fmt.Printf("Skipped %s %s in namespace %s: %s\n", result.Kind, result.Name, result.Namespace, result.Skipped)
+		case result.Name == "":
+			failed++
+			fmt.Printf("Error in namespace %s: %v\n", result.Namespace, result.Err)
+		case result.Failed():
+			failed++
+			fmt.Printf("Error scaling %s %s in namespace %s: %v\n", result.Kind, result.Name, result.Namespace, result.Err)
+		case result.Resumed && dryRun:
+			scaled++
+			fmt.Printf("Would resume %s %s in namespace %s\n", result.Kind, result.Name, result.Namespace)
+		case result.Resumed:
+			scaled++
+			fmt.Printf("Successfully resumed %s %s in namespace %s at %d replicas\n", result.Kind, result.Name, result.Namespace, result.Replicas)
+		case result.Suspended && dryRun:
+			scaled++
+			fmt.Printf("Would suspend %s %s in namespace %s\n", result.Kind, result.Name, result.Namespace)
+		case result.Suspended:
+			scaled++
+			fmt.Printf("Successfully suspended %s %s in namespace %s\n", result.Kind, result.Name, result.Namespace)
+		case dryRun:
+			scaled++
+			fmt.Printf("Would scale %s %s in namespace %s from %d to %d replicas\n",
+				result.Kind, result.Name, result.Namespace, result.PreviousReplicas, result.Replicas)
+		default:
+			scaled++
+			fmt.Printf("Successfully scaled %s %s in namespace %s from %d to %d replicas\n",
+				result.Kind, result.Name, result.Namespace, result.PreviousReplicas, result.Replicas)
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("\n%d would be scaled, %d failed, %d skipped\n", scaled, failed, skipped)
+		return
+	}
+	fmt.Printf("\n%d scaled, %d failed, %d skipped\n", scaled, failed, skipped)
+}
+
+// flagsForContext returns the connection flags for a kubeconfig context. The
+// kubeconfig, impersonation and timeout flags are kept, while cluster and user
+// come from the context. The command line flags are returned when empty.
+func flagsForContext(contextName string) *genericclioptions.ConfigFlags {
+	if contextName == "" {
+		return configFlags
+	}
+
+	flags := genericclioptions.NewConfigFlags(true)
+	flags.KubeConfig = configFlags.KubeConfig
+	flags.CacheDir = configFlags.CacheDir
+	flags.Impersonate = configFlags.Impersonate
+	flags.ImpersonateUID = configFlags.ImpersonateUID
+	flags.ImpersonateGroup = configFlags.ImpersonateGroup
+	flags.Timeout = configFlags.Timeout
+	flags.Context = &contextName
+	flags.Namespace = nil
+	return flags
+}
+
+// newClients creates the typed and dynamic Kubernetes clients from the kubectl
+// connection flags, falling back to the in-cluster config when no kubeconfig is available
+func newClients(flags *genericclioptions.ConfigFlags) (kubernetes.Interface, dynamic.Interface, error) {
+	config, err := flags.ToRESTConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error building kubeconfig: %v", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating Kubernetes client: %v", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating dynamic client: %v", err)
+	}
+
+	return clientset, dynamicClient, nil
+}
+
+// currentNamespace returns the namespace used when -n is not given: the one passed
+// down by kubectl when invoked as a plugin, otherwise the context's namespace.
+// kubectl passes down the namespace of its own context, so it is only used when
+// neither -n nor --context is given.
+func currentNamespace(flags *genericclioptions.ConfigFlags) (string, error) {
+	contextSet := flags.Context != nil && *flags.Context != ""
+	if namespace := os.Getenv("KUBECTL_PLUGINS_CURRENT_NAMESPACE"); namespace != "" && flags == configFlags && !contextSet && namespaces == "" {
+		return namespace, nil
+	}
+
+	namespace, _, err := flags.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return "", fmt.Errorf("error resolving current namespace: %v", err)
+	}
+
+	return namespace, nil
 }
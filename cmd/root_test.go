@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// addCommandsOnce adds the kind commands once for the tests using them
+var addCommandsOnce sync.Once
+
+func TestParseKindArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		kinds    []string
+		expected []string
+	}{
+		{[]string{"deploy,sts"}, []string{"deployment", "statefulset"}, []string{}},
+		{[]string{"deploy,sts", "api"}, []string{"deployment", "statefulset"}, []string{"api"}},
+		{[]string{"deployment/api", "sts/db"}, []string{"deployment"}, []string{"deployment/api", "sts/db"}},
+		{[]string{"deploy,unknown"}, nil, nil},
+		{[]string{"unknown/api"}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		kinds, args, err := parseKindArgs(tt.args)
+		if tt.kinds == nil {
+			if err == nil {
+				t.Errorf("parseKindArgs(%v) should fail, got %v, %v", tt.args, kinds, args)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(kinds, tt.kinds) || !slices.Equal(args, tt.expected) {
+			t.Errorf("parseKindArgs(%v) = %v, %v, %v, expected %v, %v", tt.args, kinds, args, err, tt.kinds, tt.expected)
+		}
+	}
+}
+
+func TestRootCommandKindArgs(t *testing.T) {
+	addCommandsOnce.Do(addCommands)
+
+	tests := []struct {
+		args    []string
+		command string
+		kinds   []string
+	}{
+		// Flags before the kinds
+		{[]string{"--context", "prod", "deploy,sts", "--replicas=0"}, "kubectl-mscale", []string{"deployment", "statefulset"}},
+		{[]string{"-n", "staging", "deploy,sts", "api", "--replicas=0"}, "kubectl-mscale", []string{"deployment", "statefulset"}},
+		// Flags of any of the kinds, not just the first
+		{[]string{"sts,hpa", "--min", "2"}, "kubectl-mscale", []string{"statefulset", "horizontalpodautoscaler"}},
+		{[]string{"deploy,cronjob", "--suspend"}, "kubectl-mscale", []string{"deployment", "cronjob"}},
+		{[]string{"deployment/api", "sts/db", "--replicas=1"}, "kubectl-mscale", []string{"deployment"}},
+		{[]string{"--context", "prod", "deployment", "api", "--replicas=1"}, "deployment", nil},
+	}
+
+	for _, tt := range tests {
+		cmd, args, err := rootCmd.Find(tt.args)
+		if err != nil {
+			t.Errorf("Find(%v) failed: %v", tt.args, err)
+			continue
+		}
+		if cmd.Name() != tt.command {
+			t.Errorf("Find(%v) = %s, expected %s", tt.args, cmd.Name(), tt.command)
+			continue
+		}
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Errorf("Parsing the flags of %v failed: %v", tt.args, err)
+			continue
+		}
+		if tt.kinds == nil {
+			continue
+		}
+
+		kinds, _, err := parseKindArgs(cmd.Flags().Args())
+		if err != nil || !slices.Equal(kinds, tt.kinds) {
+			t.Errorf("Expected kinds %v for %v, got %v: %v", tt.kinds, tt.args, kinds, err)
+		}
+	}
+}
+
+func TestCurrentNamespace(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	err := os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: local
+  cluster: {server: "https://127.0.0.1:6443"}
+contexts:
+- name: dev
+  context: {cluster: local, namespace: dev}
+- name: prod
+  context: {cluster: local, namespace: prod}
+`), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+	t.Setenv("KUBECONFIG", kubeconfig)
+	t.Setenv("KUBECTL_PLUGINS_CURRENT_NAMESPACE", "plugin")
+
+	defer func(flags *genericclioptions.ConfigFlags, namespaceList string) {
+		configFlags, namespaces = flags, namespaceList
+	}(configFlags, namespaces)
+	namespaces = ""
+
+	tests := []struct {
+		context  string
+		expected string
+	}{
+		// The namespace passed down by kubectl is of its current context
+		{"", "plugin"},
+		// An explicit context keeps its own namespace
+		{"prod", "prod"},
+	}
+
+	for _, tt := range tests {
+		configFlags = genericclioptions.NewConfigFlags(true)
+		*configFlags.Context = tt.context
+		namespace, err := currentNamespace(configFlags)
+		if err != nil || namespace != tt.expected {
+			t.Errorf("currentNamespace with context %q = %q, %v, expected %q", tt.context, namespace, err, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stenstromen/kubectl-mscale/pkg/scale"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Controller reconciles ScaleSchedule objects by running the scaling logic
+// from the scale package whenever a schedule is due
+type Controller struct {
+	clientset kubernetes.Interface
+	dynamic   dynamic.Interface
+	log       *slog.Logger
+	now       func() time.Time
+}
+
+// New creates a controller using the given typed and dynamic clients
+func New(clientset kubernetes.Interface, dynamicClient dynamic.Interface) *Controller {
+	return &Controller{
+		clientset: clientset,
+		dynamic:   dynamicClient,
+		log:       slog.Default(),
+		now:       time.Now,
+	}
+}
+
+// Run reconciles all ScaleSchedules every interval until the context is cancelled
+func (c *Controller) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.ReconcileAll(ctx); err != nil {
+			c.log.Error("Error reconciling scaleschedules", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileAll reconciles every ScaleSchedule in the cluster
+func (c *Controller) ReconcileAll(ctx context.Context) error {
+	list, err := c.dynamic.Resource(ScaleScheduleGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing scaleschedules: %v", err)
+	}
+
+	for i := range list.Items {
+		if err := c.Reconcile(ctx, &list.Items[i]); err != nil {
+			c.log.Error("Error reconciling scaleschedule", "name", list.Items[i].GetName(), "error", err)
+		}
+	}
+
+	return nil
+}
+
+// Reconcile runs a single ScaleSchedule if it is due and records the outcome in its status
+func (c *Controller) Reconcile(ctx context.Context, obj *unstructured.Unstructured) error {
+	var schedule ScaleSchedule
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &schedule); err != nil {
+		return fmt.Errorf("error decoding scaleschedule: %v", err)
+	}
+
+	status := schedule.Status
+	status.Conditions = append([]metav1.Condition(nil), schedule.Status.Conditions...)
+	status.ObservedGeneration = schedule.Generation
+	now := c.now()
+
+	cronSchedule, location, err := parseSchedule(schedule.Spec)
+	policy, policyErr := c.policy(ctx, schedule.Spec.Policy)
+	switch {
+	case err != nil:
+		status.NextRunTime = nil
+		setCondition(&status, ConditionReady, metav1.ConditionFalse, "InvalidSchedule", err.Error(), schedule.Generation)
+
+	case schedule.Spec.Suspend:
+		status.NextRunTime = nil
+		setCondition(&status, ConditionReady, metav1.ConditionFalse, "Suspended", "Schedule is suspended", schedule.Generation)
+
+	case policyErr != nil:
+		status.NextRunTime = nil
+		reason := "InvalidPolicy"
+		if apierrors.IsNotFound(policyErr) {
+			reason = "PolicyNotFound"
+		}
+		setCondition(&status, ConditionReady, metav1.ConditionFalse, reason, policyErr.Error(), schedule.Generation)
+
+	default:
+		last := schedule.CreationTimestamp.Time
+		if status.LastRunTime != nil {
+			last = status.LastRunTime.Time
+		}
+
+		next := cronSchedule.Next(last.In(location))
+		if !next.After(now) {
+			status.Results = c.run(ctx, schedule.Spec, policy)
+			status.LastRunTime = &metav1.Time{Time: now}
+			next = cronSchedule.Next(now.In(location))
+
+			scaled, failed := 0, 0
+			for _, result := range status.Results {
+				switch {
+				case result.Succeeded:
+					scaled++
+				case !result.Skipped:
+					failed++
+				}
+			}
+			c.log.Info("Ran scaleschedule", "name", schedule.Name, "scaled", scaled, "failed", failed)
+			if failed > 0 {
+				setCondition(&status, ConditionSucceeded, metav1.ConditionFalse, "RunFailed",
+					fmt.Sprintf("%d of %d targets failed to scale", failed, len(status.Results)), schedule.Generation)
+			} else {
+				setCondition(&status, ConditionSucceeded, metav1.ConditionTrue, "RunSucceeded",
+					fmt.Sprintf("Scaled %d targets to %d replicas", scaled, schedule.Spec.Replicas), schedule.Generation)
+			}
+		}
+
+		status.NextRunTime = &metav1.Time{Time: next}
+		setCondition(&status, ConditionReady, metav1.ConditionTrue, "Scheduled",
+			fmt.Sprintf("Next run at %s", next.Format(time.RFC3339)), schedule.Generation)
+	}
+
+	if equality.Semantic.DeepEqual(status, schedule.Status) {
+		return nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return fmt.Errorf("error encoding status: %v", err)
+	}
+
+	updated := obj.DeepCopy()
+	if err := unstructured.SetNestedField(updated.Object, content, "status"); err != nil {
+		return fmt.Errorf("error setting status: %v", err)
+	}
+
+	if _, err := c.dynamic.Resource(ScaleScheduleGVR).UpdateStatus(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error updating status: %v", err)
+	}
+
+	return nil
+}
+
+// policy returns the spec of the named ScalePolicy, or an empty spec if name is empty
+func (c *Controller) policy(ctx context.Context, name string) (ScalePolicySpec, error) {
+	if name == "" {
+		return ScalePolicySpec{}, nil
+	}
+
+	obj, err := c.dynamic.Resource(ScalePolicyGVR).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return ScalePolicySpec{}, err
+	}
+
+	var policy ScalePolicy
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &policy); err != nil {
+		return ScalePolicySpec{}, fmt.Errorf("error decoding scalepolicy %s: %v", name, err)
+	}
+	opts := policy.Spec.options()
+	if err := opts.Validate(); err != nil {
+		return ScalePolicySpec{}, fmt.Errorf("invalid scalepolicy %s: %v", name, err)
+	}
+	return policy.Spec, nil
+}
+
+// options returns the scale options of a policy
+func (p ScalePolicySpec) options() scale.Options {
+	opts := scale.Options{
+		CurrentReplicas:   -1,
+		ForceConflicts:    p.ForceConflicts,
+		IgnorePDB:         p.IgnorePDB,
+		AdjustPDB:         p.AdjustPDB,
+		IgnoreQuota:       p.IgnoreQuota,
+		SkipGitOps:        p.SkipGitOps,
+		SuspendGitOps:     p.SuspendGitOps,
+		ArgoCDNamespace:   p.ArgoCDNamespace,
+		HPAMode:           scale.HPAMode(p.HPA),
+		ActiveJobs:        scale.ActiveJobsPolicy(p.ActiveJobs),
+		Wait:              p.Wait,
+		ExcludeNames:      p.ExcludeNames,
+		ExcludeNamespaces: p.ExcludeNamespaces,
+	}
+	if p.WaitTimeout != nil {
+		opts.WaitTimeout = p.WaitTimeout.Duration
+	}
+	return opts
+}
+
+// run scales every target of the spec with the options of the policy and
+// returns one result per resource
+func (c *Controller) run(ctx context.Context, spec ScaleScheduleSpec, policy ScalePolicySpec) []TargetResult {
+	currentReplicas := -1
+	if spec.CurrentReplicas != nil {
+		currentReplicas = *spec.CurrentReplicas
+	}
+
+	var results []TargetResult
+	for _, target := range spec.Targets {
+		opts := policy.options()
+		opts.Replicas = spec.Replicas
+		opts.CurrentReplicas = currentReplicas
+		opts.ForceConflicts = opts.ForceConflicts || spec.ForceConflicts
+		opts.ExcludeNamespaces = append(slices.Clone(policy.ExcludeNamespaces), target.ExcludeNamespaces...)
+		opts.ExcludeNames = append(slices.Clone(policy.ExcludeNames), target.ExcludeNames...)
+		if err := opts.Validate(); err != nil {
+			results = append(results, TargetResult{Kind: target.Kind, Replicas: spec.Replicas, Message: err.Error()})
+			continue
+		}
+		scaler := scale.NewScaler(c.clientset, c.dynamic, opts)
+
+		var scaled []scale.Result
+		var err error
+		if len(target.Names) == 0 {
+			scaled, err = scaler.ScaleAll(ctx, target.Kind, target.Namespaces)
+		} else {
+			scaled, err = scaler.ScaleNames(ctx, target.Kind, target.Names, target.Namespaces)
+		}
+		if err != nil {
+			scaled = append(scaled, scale.Result{Kind: target.Kind, Replicas: spec.Replicas, Err: err})
+		}
+
+		for _, result := range scaled {
+			targetResult := TargetResult{
+				Kind:      result.Kind,
+				Namespace: result.Namespace,
+				Name:      result.Name,
+				Replicas:  result.Replicas,
+				Succeeded: result.Succeeded(),
+				Skipped:   result.Skipped != "",
+				Message:   result.Skipped,
+			}
+			if len(result.Warnings) > 0 {
+				targetResult.Message = strings.Join(result.Warnings, "; ")
+			}
+			if result.Err != nil {
+				targetResult.Message = result.Err.Error()
+			}
+			results = append(results, targetResult)
+		}
+	}
+
+	return results
+}
+
+// parseSchedule parses the cron expression and time zone of a spec
+func parseSchedule(spec ScaleScheduleSpec) (cron.Schedule, *time.Location, error) {
+	location := time.UTC
+	if spec.TimeZone != "" {
+		loc, err := time.LoadLocation(spec.TimeZone)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid time zone %q: %v", spec.TimeZone, err)
+		}
+		location = loc
+	}
+
+	schedule, err := cron.ParseStandard(spec.Schedule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid schedule %q: %v", spec.Schedule, err)
+	}
+
+	return schedule, location, nil
+}
+
+// setCondition sets a condition on the status, only bumping the transition time when it changes
+func setCondition(status *ScaleScheduleStatus, conditionType string, conditionStatus metav1.ConditionStatus, reason, message string, generation int64) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
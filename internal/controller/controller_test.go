@@ -0,0 +1,262 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func newScaleSchedule(t *testing.T, name string, created time.Time, spec ScaleScheduleSpec) *unstructured.Unstructured {
+	schedule := &ScaleSchedule{
+		TypeMeta: metav1.TypeMeta{APIVersion: "mscale.io/v1alpha1", Kind: "ScaleSchedule"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Generation:        1,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: spec,
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(schedule)
+	if err != nil {
+		t.Fatalf("Failed to convert scaleschedule: %v", err)
+	}
+	return &unstructured.Unstructured{Object: content}
+}
+
+func newScalePolicy(t *testing.T, name string, spec ScalePolicySpec) *unstructured.Unstructured {
+	policy := &ScalePolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "mscale.io/v1alpha1", Kind: "ScalePolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(policy)
+	if err != nil {
+		t.Fatalf("Failed to convert scalepolicy: %v", err)
+	}
+	return &unstructured.Unstructured{Object: content}
+}
+
+func newController(clientset *fake.Clientset, objects ...runtime.Object) *Controller {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			ScaleScheduleGVR: "ScaleScheduleList",
+			ScalePolicyGVR:   "ScalePolicyList",
+		}, objects...)
+	return New(clientset, dynamicClient)
+}
+
+func getStatus(t *testing.T, c *Controller, name string) ScaleScheduleStatus {
+	obj, err := c.dynamic.Resource(ScaleScheduleGVR).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get scaleschedule: %v", err)
+	}
+
+	var schedule ScaleSchedule
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &schedule); err != nil {
+		t.Fatalf("Failed to decode scaleschedule: %v", err)
+	}
+	return schedule.Status
+}
+
+func TestReconcileRunsDueSchedule(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "staging"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+	})
+
+	now := time.Date(2025, 6, 2, 19, 30, 0, 0, time.UTC)
+	obj := newScaleSchedule(t, "nightly", now.Add(-2*time.Hour), ScaleScheduleSpec{
+		Schedule: "0 19 * * *",
+		Replicas: 0,
+		Targets:  []ScaleTarget{{Kind: "deploy", Namespaces: []string{"staging"}}},
+	})
+
+	c := newController(clientset, obj)
+	c.now = func() time.Time { return now }
+
+	if err := c.ReconcileAll(context.TODO()); err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+
+	deployment, err := clientset.AppsV1().Deployments("staging").Get(context.TODO(), "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if *deployment.Spec.Replicas != 0 {
+		t.Errorf("Expected 0 replicas, got %d", *deployment.Spec.Replicas)
+	}
+
+	status := getStatus(t, c, "nightly")
+	if status.LastRunTime == nil || !status.LastRunTime.Time.Equal(now) {
+		t.Errorf("Expected last run time %s, got %v", now, status.LastRunTime)
+	}
+	if expected := time.Date(2025, 6, 3, 19, 0, 0, 0, time.UTC); status.NextRunTime == nil || !status.NextRunTime.Time.Equal(expected) {
+		t.Errorf("Expected next run time %s, got %v", expected, status.NextRunTime)
+	}
+	if len(status.Results) != 1 || !status.Results[0].Succeeded || status.Results[0].Name != "api" {
+		t.Errorf("Expected one successful result for api, got %+v", status.Results)
+	}
+	if !meta.IsStatusConditionTrue(status.Conditions, ConditionSucceeded) {
+		t.Errorf("Expected Succeeded condition to be true, got %+v", status.Conditions)
+	}
+}
+
+func TestReconcileWaitsForNextRun(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "staging"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+	})
+
+	now := time.Date(2025, 6, 2, 18, 30, 0, 0, time.UTC)
+	obj := newScaleSchedule(t, "nightly", now.Add(-time.Hour), ScaleScheduleSpec{
+		Schedule: "0 19 * * *",
+		Replicas: 0,
+		Targets:  []ScaleTarget{{Kind: "deployment", Names: []string{"api"}, Namespaces: []string{"staging"}}},
+	})
+
+	c := newController(clientset, obj)
+	c.now = func() time.Time { return now }
+
+	if err := c.ReconcileAll(context.TODO()); err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+
+	deployment, err := clientset.AppsV1().Deployments("staging").Get(context.TODO(), "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if *deployment.Spec.Replicas != 3 {
+		t.Errorf("Expected deployment to be left at 3 replicas, got %d", *deployment.Spec.Replicas)
+	}
+
+	status := getStatus(t, c, "nightly")
+	if status.LastRunTime != nil {
+		t.Errorf("Expected no last run time, got %v", status.LastRunTime)
+	}
+	if !meta.IsStatusConditionTrue(status.Conditions, ConditionReady) {
+		t.Errorf("Expected Ready condition to be true, got %+v", status.Conditions)
+	}
+}
+
+func TestReconcileInvalidSchedule(t *testing.T) {
+	obj := newScaleSchedule(t, "broken", time.Now(), ScaleScheduleSpec{
+		Schedule: "every night",
+		Targets:  []ScaleTarget{{Kind: "deployment", Namespaces: []string{"staging"}}},
+	})
+
+	c := newController(fake.NewSimpleClientset(), obj)
+	if err := c.ReconcileAll(context.TODO()); err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+
+	condition := meta.FindStatusCondition(getStatus(t, c, "broken").Conditions, ConditionReady)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "InvalidSchedule" {
+		t.Errorf("Expected Ready=False with reason InvalidSchedule, got %+v", condition)
+	}
+}
+
+func TestReconcileAppliesPolicy(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "staging"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-canary", Namespace: "staging"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(1)},
+		},
+	)
+
+	now := time.Date(2025, 6, 2, 19, 30, 0, 0, time.UTC)
+	schedule := newScaleSchedule(t, "nightly", now.Add(-2*time.Hour), ScaleScheduleSpec{
+		Schedule: "0 19 * * *",
+		Replicas: 0,
+		Policy:   "careful",
+		Targets:  []ScaleTarget{{Kind: "deployment", Namespaces: []string{"staging"}}},
+	})
+	policy := newScalePolicy(t, "careful", ScalePolicySpec{ExcludeNames: []string{"*-canary"}, HPA: "skip"})
+
+	c := newController(clientset, schedule, policy)
+	c.now = func() time.Time { return now }
+
+	if err := c.ReconcileAll(context.TODO()); err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+
+	for name, expected := range map[string]int32{"api": 0, "api-canary": 1} {
+		deployment, err := clientset.AppsV1().Deployments("staging").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment: %v", err)
+		}
+		if *deployment.Spec.Replicas != expected {
+			t.Errorf("Expected %s at %d replicas, got %d", name, expected, *deployment.Spec.Replicas)
+		}
+	}
+}
+
+func TestReconcileInvalidPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies []runtime.Object
+		reason   string
+	}{
+		{name: "missing", reason: "PolicyNotFound"},
+		{
+			name:     "invalid",
+			policies: []runtime.Object{newScalePolicy(t, "careful", ScalePolicySpec{HPA: "sometimes"})},
+			reason:   "InvalidPolicy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "staging"},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+			})
+
+			now := time.Date(2025, 6, 2, 19, 30, 0, 0, time.UTC)
+			schedule := newScaleSchedule(t, "nightly", now.Add(-2*time.Hour), ScaleScheduleSpec{
+				Schedule: "0 19 * * *",
+				Replicas: 0,
+				Policy:   "careful",
+				Targets:  []ScaleTarget{{Kind: "deployment", Namespaces: []string{"staging"}}},
+			})
+
+			c := newController(clientset, append(tt.policies, schedule)...)
+			c.now = func() time.Time { return now }
+
+			if err := c.ReconcileAll(context.TODO()); err != nil {
+				t.Fatalf("Failed to reconcile: %v", err)
+			}
+
+			deployment, err := clientset.AppsV1().Deployments("staging").Get(context.TODO(), "api", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Failed to get deployment: %v", err)
+			}
+			if *deployment.Spec.Replicas != 3 {
+				t.Errorf("Expected deployment to be left at 3 replicas, got %d", *deployment.Spec.Replicas)
+			}
+
+			condition := meta.FindStatusCondition(getStatus(t, c, "nightly").Conditions, ConditionReady)
+			if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != tt.reason {
+				t.Errorf("Expected Ready=False with reason %s, got %+v", tt.reason, condition)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ScaleScheduleGVR is the resource served by the ScaleSchedule CRD
+var ScaleScheduleGVR = schema.GroupVersionResource{
+	Group:    "mscale.io",
+	Version:  "v1alpha1",
+	Resource: "scaleschedules",
+}
+
+// ScalePolicyGVR is the resource served by the ScalePolicy CRD
+var ScalePolicyGVR = schema.GroupVersionResource{
+	Group:    "mscale.io",
+	Version:  "v1alpha1",
+	Resource: "scalepolicies",
+}
+
+// Condition types reported on a ScaleSchedule
+const (
+	ConditionReady     = "Ready"
+	ConditionSucceeded = "Succeeded"
+)
+
+// ScaleSchedule is a cluster-scoped object describing what to scale and when
+type ScaleSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScaleScheduleSpec   `json:"spec"`
+	Status ScaleScheduleStatus `json:"status,omitempty"`
+}
+
+// ScaleScheduleSpec is the desired state of a ScaleSchedule
+type ScaleScheduleSpec struct {
+	// Schedule is a standard cron expression, as used by CronJobs
+	Schedule string `json:"schedule"`
+	// TimeZone is an IANA time zone name the schedule is evaluated in, UTC if empty
+	TimeZone string `json:"timeZone,omitempty"`
+	// Suspend stops future runs without deleting the schedule
+	Suspend bool `json:"suspend,omitempty"`
+	// Replicas is the desired replica count applied to every target
+	Replicas int `json:"replicas"`
+	// CurrentReplicas is an optional precondition, matching --current-replicas
+	CurrentReplicas *int `json:"currentReplicas,omitempty"`
+	// ForceConflicts takes ownership of the replica field when other field managers applied it, matching --force-conflicts
+	ForceConflicts bool `json:"forceConflicts,omitempty"`
+	// Policy is the name of a ScalePolicy with the options the targets are scaled with
+	Policy string `json:"policy,omitempty"`
+	// Targets lists the resources to scale
+	Targets []ScaleTarget `json:"targets"`
+}
+
+// ScaleTarget selects resources of one kind across namespaces
+type ScaleTarget struct {
+	// Kind is any resource type or alias accepted by the CLI, e.g. deployment or sts
+	Kind string `json:"kind"`
+	// Names or glob patterns of the resources to scale; all resources of Kind are scaled if empty
+	Names []string `json:"names,omitempty"`
+	// Namespaces or glob patterns of namespaces to scale in
+	Namespaces []string `json:"namespaces"`
+	// ExcludeNames are glob patterns of resource names to leave alone
+	ExcludeNames []string `json:"excludeNames,omitempty"`
+	// ExcludeNamespaces are glob patterns of namespaces to leave alone
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+}
+
+// ScalePolicy is a cluster-scoped object describing how ScaleSchedules
+// referencing it scale their targets
+type ScalePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ScalePolicySpec `json:"spec"`
+}
+
+// ScalePolicySpec holds the scaling options of a ScalePolicy, matching the CLI flags
+type ScalePolicySpec struct {
+	// ForceConflicts takes ownership of the replica field when other field managers applied it, matching --force-conflicts
+	ForceConflicts bool `json:"forceConflicts,omitempty"`
+	// IgnorePDB scales down even when a PodDisruptionBudget would be violated, matching --ignore-pdb
+	IgnorePDB bool `json:"ignorePDB,omitempty"`
+	// AdjustPDB relaxes violated PodDisruptionBudgets, matching --adjust-pdb
+	AdjustPDB bool `json:"adjustPDB,omitempty"`
+	// IgnoreQuota scales up even when a ResourceQuota would be exceeded, matching --ignore-quota
+	IgnoreQuota bool `json:"ignoreQuota,omitempty"`
+	// SkipGitOps leaves resources managed by Argo CD or Flux alone, matching --skip-gitops
+	SkipGitOps bool `json:"skipGitOps,omitempty"`
+	// SuspendGitOps suspends GitOps reconciliation before scaling, matching --suspend-gitops
+	SuspendGitOps bool `json:"suspendGitOps,omitempty"`
+	// ArgoCDNamespace is the namespace of Argo CD Applications, matching --argocd-namespace
+	ArgoCDNamespace string `json:"argoCDNamespace,omitempty"`
+	// HPA is how resources targeted by a HorizontalPodAutoscaler are scaled, matching --hpa
+	HPA string `json:"hpa,omitempty"`
+	// ActiveJobs is what happens to running Jobs of CronJobs, matching --active-jobs
+	ActiveJobs string `json:"activeJobs,omitempty"`
+	// Wait waits for the scaled resources to become ready, matching --wait
+	Wait bool `json:"wait,omitempty"`
+	// WaitTimeout bounds each wait, matching --wait-timeout
+	WaitTimeout *metav1.Duration `json:"waitTimeout,omitempty"`
+	// ExcludeNames are glob patterns of resource names every target leaves alone
+	ExcludeNames []string `json:"excludeNames,omitempty"`
+	// ExcludeNamespaces are glob patterns of namespaces every target leaves alone
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+}
+
+// ScaleScheduleStatus is the observed state of a ScaleSchedule
+type ScaleScheduleStatus struct {
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	LastRunTime        *metav1.Time       `json:"lastRunTime,omitempty"`
+	NextRunTime        *metav1.Time       `json:"nextRunTime,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+	Results            []TargetResult     `json:"results,omitempty"`
+}
+
+// TargetResult is the outcome of scaling a single resource during the last run
+type TargetResult struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name,omitempty"`
+	Replicas  int    `json:"replicas"`
+	Succeeded bool   `json:"succeeded"`
+	Skipped   bool   `json:"skipped,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
@@ -0,0 +1,154 @@
+package scale
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Annotations recording the bounds of a BoundsKind resource before mscale first changed them
+const (
+	OriginalMinReplicasAnnotation = "mscale.io/original-min-replicas"
+	OriginalMaxReplicasAnnotation = "mscale.io/original-max-replicas"
+)
+
+// Bounds are the min and max replicas of an autoscaled resource
+type Bounds struct {
+	Min int
+	// Max is 0 for kinds where that means unlimited, such as Knative services
+	Max int
+}
+
+// String formats the bounds as min-max
+func (b Bounds) String() string {
+	if b.Max == 0 {
+		return fmt.Sprintf("%d-unlimited", b.Min)
+	}
+	return fmt.Sprintf("%d-%d", b.Min, b.Max)
+}
+
+// ScaledTo returns the bounds with Min set to replicas and Max scaled by the
+// same ratio, but never below replicas. An unlimited Max stays unlimited, and
+// Max is kept when scaling to zero, so that the resource can still scale up.
+func (b Bounds) ScaledTo(replicas int) Bounds {
+	if b.Max == 0 {
+		return Bounds{Min: replicas}
+	}
+	if b.Min <= 0 || replicas == 0 {
+		return Bounds{Min: replicas, Max: max(b.Max, replicas)}
+	}
+	scaled := int(math.Round(float64(b.Max) * float64(replicas) / float64(b.Min)))
+	return Bounds{Min: replicas, Max: max(scaled, replicas)}
+}
+
+// scaledToMax returns the bounds with Max set to maxReplicas and Min scaled by
+// the same ratio, keeping a non-zero Min above zero
+func (b Bounds) scaledToMax(maxReplicas int) Bounds {
+	if b.Max <= 0 || maxReplicas <= 0 {
+		return Bounds{Min: min(b.Min, maxReplicas), Max: maxReplicas}
+	}
+	scaled := int(math.Round(float64(b.Min) * float64(maxReplicas) / float64(b.Max)))
+	if b.Min > 0 {
+		scaled = max(scaled, 1)
+	}
+	return Bounds{Min: min(scaled, maxReplicas), Max: maxReplicas}
+}
+
+// annotations returns the annotations recording b as the original bounds
+func (b Bounds) annotations() map[string]string {
+	return map[string]string{
+		OriginalMinReplicasAnnotation: strconv.Itoa(b.Min),
+		OriginalMaxReplicasAnnotation: strconv.Itoa(b.Max),
+	}
+}
+
+// originalBounds reads the original bounds recorded in annotations, nil if none
+func originalBounds(annotations map[string]string) (*Bounds, error) {
+	minValue, minOK := annotations[OriginalMinReplicasAnnotation]
+	maxValue, maxOK := annotations[OriginalMaxReplicasAnnotation]
+	if !minOK && !maxOK {
+		return nil, nil
+	}
+
+	minReplicas, err := strconv.Atoi(minValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", OriginalMinReplicasAnnotation, err)
+	}
+	maxReplicas, err := strconv.Atoi(maxValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", OriginalMaxReplicasAnnotation, err)
+	}
+	return &Bounds{Min: minReplicas, Max: maxReplicas}, nil
+}
+
+// resolveBound resolves a bound given as an absolute count, or relative to the
+// current value with a leading + or -, e.g. +2
+func resolveBound(value string, current int) (int, error) {
+	value = strings.TrimSpace(value)
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid replica bound %q, expected a count such as 4, +2 or -1", value)
+	}
+	if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+		return current + n, nil
+	}
+	return n, nil
+}
+
+// targetBounds returns the bounds a BoundsKind resource is scaled to, and the
+// original bounds to record on it. Replicas set the min replicas, negative if
+// unset. Options.MinReplicas and Options.MaxReplicas set the bounds directly,
+// and a bound that is not set keeps its ratio to the other unless
+// Options.IndependentBounds is set. Options.RestoreBounds returns the recorded
+// original bounds instead.
+func (s *Scaler) targetBounds(current Bounds, original *Bounds, replicas int) (Bounds, *Bounds, error) {
+	if s.opts.RestoreBounds {
+		if original == nil {
+			return current, nil, nil
+		}
+		return *original, nil, nil
+	}
+
+	next := current
+	minSet, maxSet := false, false
+	var err error
+	switch {
+	case s.opts.MinReplicas != "":
+		if next.Min, err = resolveBound(s.opts.MinReplicas, current.Min); err != nil {
+			return current, original, err
+		}
+		minSet = true
+	case replicas >= 0:
+		next.Min, minSet = replicas, true
+	}
+	if s.opts.MaxReplicas != "" {
+		if next.Max, err = resolveBound(s.opts.MaxReplicas, current.Max); err != nil {
+			return current, original, err
+		}
+		maxSet = true
+	}
+
+	switch {
+	case s.opts.IndependentBounds:
+	case minSet && !maxSet:
+		next.Max = current.ScaledTo(next.Min).Max
+	case maxSet && !minSet:
+		next.Min = current.scaledToMax(next.Max).Min
+	}
+
+	// A max of 0 is only kept when it already meant unlimited
+	switch {
+	case next.Min < 0:
+		return current, original, fmt.Errorf("min replicas cannot be negative, got %d", next.Min)
+	case next.Max < 0 || next.Max == 0 && current.Max != 0:
+		return current, original, fmt.Errorf("max replicas must be at least 1, got %d", next.Max)
+	case next.Max != 0 && next.Min > next.Max:
+		return current, original, fmt.Errorf("min replicas %d exceed max replicas %d", next.Min, next.Max)
+	}
+
+	if original == nil && next != current {
+		original = &current
+	}
+	return next, original, nil
+}
@@ -0,0 +1,263 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodePoolLabels are the node labels checked, in order, to group nodes into
+// pools. Nodes without any of them are grouped in the "default" pool.
+var NodePoolLabels = []string{
+	"cloud.google.com/gke-nodepool",
+	"eks.amazonaws.com/nodegroup",
+	"kubernetes.azure.com/agentpool",
+	"karpenter.sh/nodepool",
+	"node.kubernetes.io/instance-type",
+}
+
+// CapacityEstimate is the estimated fit of the pods added by a scale-up on the
+// nodes of a cluster
+type CapacityEstimate struct {
+	Pools []PoolCapacity
+	// Unschedulable lists the resources with added pods that fit on no node
+	Unschedulable []UnschedulablePods
+}
+
+// Fits reports whether all added pods are estimated to fit
+func (e CapacityEstimate) Fits() bool {
+	return len(e.Unschedulable) == 0
+}
+
+// PoolCapacity is the free capacity of a node pool and the added pods estimated to land on it
+type PoolCapacity struct {
+	Name  string
+	Nodes int
+	// FreeCPU, FreeMemory and FreePods are allocatable minus the requests of the
+	// pods already running on schedulable nodes, before scaling
+	FreeCPU    resource.Quantity
+	FreeMemory resource.Quantity
+	FreePods   int64
+	// AddedPods, AddedCPU and AddedMemory are the added pods placed in the pool and their requests
+	AddedPods   int
+	AddedCPU    resource.Quantity
+	AddedMemory resource.Quantity
+}
+
+// UnschedulablePods is the number of added pods of a resource that fit on no node
+type UnschedulablePods struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Pods      int
+}
+
+// nodeCapacity is the remaining capacity of a single node during estimation
+type nodeCapacity struct {
+	node   corev1.Node
+	pool   int
+	cpu    resource.Quantity
+	memory resource.Quantity
+	pods   int64
+}
+
+// EstimateCapacity estimates whether the pods added by the scale-ups among the
+// results fit in the cluster. Each added pod is placed on the eligible node with
+// the most free CPU, the way the scheduler spreads pods by default. Eligibility
+// is coarse: nodeSelector, taints and tolerations, cordoned and NotReady nodes
+// are respected, while affinity, topology spread and ports are not.
+func (s *Scaler) EstimateCapacity(ctx context.Context, results []Result) (CapacityEstimate, error) {
+	var estimate CapacityEstimate
+
+	nodes, err := s.clients.Kubernetes.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return estimate, fmt.Errorf("error listing nodes: %v", err)
+	}
+
+	pods, err := s.clients.Kubernetes.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return estimate, fmt.Errorf("error listing pods: %v", err)
+	}
+
+	poolIndex := map[string]int{}
+	nodeIndex := map[string]int{}
+	var capacities []*nodeCapacity
+	for _, node := range nodes.Items {
+		if !nodeSchedulable(node) {
+			continue
+		}
+
+		pool := nodePool(node)
+		if _, ok := poolIndex[pool]; !ok {
+			poolIndex[pool] = len(estimate.Pools)
+			estimate.Pools = append(estimate.Pools, PoolCapacity{Name: pool})
+		}
+
+		nodeIndex[node.Name] = len(capacities)
+		capacities = append(capacities, &nodeCapacity{
+			node:   node,
+			pool:   poolIndex[pool],
+			cpu:    node.Status.Allocatable.Cpu().DeepCopy(),
+			memory: node.Status.Allocatable.Memory().DeepCopy(),
+			pods:   node.Status.Allocatable.Pods().Value(),
+		})
+	}
+
+	for _, pod := range pods.Items {
+		index, ok := nodeIndex[pod.Spec.NodeName]
+		if !ok || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		requests := podResources(&corev1.PodTemplateSpec{Spec: pod.Spec}, nil)
+		capacities[index].cpu.Sub(requests[corev1.ResourceRequestsCPU])
+		capacities[index].memory.Sub(requests[corev1.ResourceRequestsMemory])
+		capacities[index].pods--
+	}
+
+	for _, capacity := range capacities {
+		pool := &estimate.Pools[capacity.pool]
+		pool.Nodes++
+		pool.FreeCPU.Add(capacity.cpu)
+		pool.FreeMemory.Add(capacity.memory)
+		pool.FreePods += capacity.pods
+	}
+
+	for _, result := range results {
+		added := result.Replicas - result.PreviousReplicas
+		if !result.Succeeded() || result.PreviousReplicas < 0 || added <= 0 {
+			continue
+		}
+
+		template, err := s.podTemplate(ctx, result.Kind, result.Namespace, result.Name)
+		if err != nil {
+			return estimate, err
+		}
+		if template == nil {
+			continue
+		}
+
+		var limitRanges []corev1.LimitRange
+		if list, err := s.clients.Kubernetes.CoreV1().LimitRanges(result.Namespace).List(ctx, metav1.ListOptions{}); err == nil {
+			limitRanges = list.Items
+		}
+		requests := podResources(template, limitRanges)
+		cpu, memory := requests[corev1.ResourceRequestsCPU], requests[corev1.ResourceRequestsMemory]
+
+		unschedulable := 0
+		for i := 0; i < added; i++ {
+			var best *nodeCapacity
+			for _, capacity := range capacities {
+				if capacity.pods < 1 || capacity.cpu.Cmp(cpu) < 0 || capacity.memory.Cmp(memory) < 0 ||
+					!podFitsNode(template, capacity.node) {
+					continue
+				}
+				if best == nil || capacity.cpu.Cmp(best.cpu) > 0 {
+					best = capacity
+				}
+			}
+
+			if best == nil {
+				unschedulable++
+				continue
+			}
+
+			best.cpu.Sub(cpu)
+			best.memory.Sub(memory)
+			best.pods--
+
+			pool := &estimate.Pools[best.pool]
+			pool.AddedPods++
+			pool.AddedCPU.Add(cpu)
+			pool.AddedMemory.Add(memory)
+		}
+
+		if unschedulable > 0 {
+			estimate.Unschedulable = append(estimate.Unschedulable, UnschedulablePods{
+				Kind:      result.Kind,
+				Namespace: result.Namespace,
+				Name:      result.Name,
+				Pods:      unschedulable,
+			})
+		}
+	}
+
+	sort.Slice(estimate.Pools, func(i, j int) bool {
+		return estimate.Pools[i].Name < estimate.Pools[j].Name
+	})
+	return estimate, nil
+}
+
+// podTemplate returns the pod template of a resource, or nil for kinds without one
+func (s *Scaler) podTemplate(ctx context.Context, resourceType, namespace, name string) (*corev1.PodTemplateSpec, error) {
+	kind, err := Lookup(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	templateKind, ok := kind.(PodTemplateKind)
+	if !ok {
+		return nil, nil
+	}
+
+	template, err := templateKind.PodTemplate(ctx, s.clients, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("error getting pod template of %s %s: %v", kind.Name(), name, err)
+	}
+	return template, nil
+}
+
+// nodePool returns the pool a node belongs to, based on NodePoolLabels
+func nodePool(node corev1.Node) string {
+	for _, label := range NodePoolLabels {
+		if pool, ok := node.Labels[label]; ok {
+			return pool
+		}
+	}
+	return "default"
+}
+
+// nodeSchedulable reports whether new pods can be scheduled on a node at all
+func nodeSchedulable(node corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podFitsNode reports whether the node matches the template's nodeSelector and
+// the template tolerates the node's scheduling taints
+func podFitsNode(template *corev1.PodTemplateSpec, node corev1.Node) bool {
+	for key, value := range template.Spec.NodeSelector {
+		if node.Labels[key] != value {
+			return false
+		}
+	}
+
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+
+		tolerated := false
+		for _, toleration := range template.Spec.Tolerations {
+			if toleration.ToleratesTaint(&taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
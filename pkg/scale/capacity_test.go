@@ -0,0 +1,87 @@
+package scale
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newNode returns a ready node in a pool with 2 CPUs, 4Gi of memory and room for 10 pods
+func newNode(name, pool string, taints ...corev1.Taint) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"cloud.google.com/gke-nodepool": pool}},
+		Spec:       corev1.NodeSpec{Taints: taints},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+				corev1.ResourcePods:   resource.MustParse("10"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func TestEstimateCapacity(t *testing.T) {
+	oneCPU := corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}}
+
+	// Two general nodes, one of them half full, and a tainted GPU node the deployment does not tolerate
+	clientset := fake.NewSimpleClientset(
+		newNode("general-1", "general"),
+		newNode("general-2", "general"),
+		newNode("gpu-1", "gpu", corev1.Taint{Key: "gpu", Effect: corev1.TaintEffectNoSchedule}),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default"},
+			Spec:       corev1.PodSpec{NodeName: "general-1", Containers: []corev1.Container{{Name: "app", Resources: oneCPU}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: int32Ptr(0),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "api", Resources: oneCPU}}},
+				},
+			},
+		},
+	)
+
+	scaler := NewScaler(clientset, nil, Options{Replicas: 5, CurrentReplicas: -1, DryRun: true})
+	results, err := scaler.ScaleNames(context.TODO(), "deployment", []string{"api"}, []string{"default"})
+	if err != nil {
+		t.Fatalf("Failed to plan scaling: %v", err)
+	}
+
+	deployment, err := clientset.AppsV1().Deployments("default").Get(context.TODO(), "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if *deployment.Spec.Replicas != 0 {
+		t.Errorf("Expected a dry run to leave 0 replicas, got %d", *deployment.Spec.Replicas)
+	}
+
+	estimate, err := scaler.EstimateCapacity(context.TODO(), results)
+	if err != nil {
+		t.Fatalf("Failed to estimate capacity: %v", err)
+	}
+
+	// 3 CPUs are free in the general pool, so 3 of the 5 pods fit
+	if len(estimate.Pools) != 2 {
+		t.Fatalf("Expected 2 pools, got %v", estimate.Pools)
+	}
+	general, gpu := estimate.Pools[0], estimate.Pools[1]
+	if general.Name != "general" || general.Nodes != 2 || general.AddedPods != 3 || general.FreeCPU.Cmp(resource.MustParse("3")) != 0 {
+		t.Errorf("Unexpected general pool estimate: %+v", general)
+	}
+	if gpu.Name != "gpu" || gpu.AddedPods != 0 {
+		t.Errorf("Unexpected gpu pool estimate: %+v", gpu)
+	}
+
+	if estimate.Fits() || len(estimate.Unschedulable) != 1 || estimate.Unschedulable[0].Pods != 2 {
+		t.Errorf("Expected 2 unschedulable pods, got %v", estimate.Unschedulable)
+	}
+}
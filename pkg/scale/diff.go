@@ -0,0 +1,69 @@
+package scale
+
+import "sort"
+
+// DiffType describes how a resource differs between a source and a target
+type DiffType string
+
+const (
+	// DiffMismatch means the resource exists in both but with different desired replicas
+	DiffMismatch DiffType = "mismatch"
+	// DiffMissing means the resource exists in the source but not in the target
+	DiffMissing DiffType = "missing"
+	// DiffExtra means the resource exists in the target but not in the source
+	DiffExtra DiffType = "extra"
+)
+
+// Difference is a resource whose replicas differ between a source and a target
+type Difference struct {
+	Kind string
+	Name string
+	Type DiffType
+	// SourceReplicas and TargetReplicas are the desired replicas, -1 when the resource is absent
+	SourceReplicas int
+	TargetReplicas int
+}
+
+// Diff compares the desired replicas of same-named resources of the same kind,
+// ignoring namespaces, and returns the differences sorted by kind and name
+func Diff(source, target []WorkloadStatus) []Difference {
+	type key struct{ kind, name string }
+
+	targets := make(map[key]WorkloadStatus, len(target))
+	for _, status := range target {
+		targets[key{status.Kind, status.Name}] = status
+	}
+
+	var differences []Difference
+	seen := make(map[key]bool, len(source))
+	for _, status := range source {
+		k := key{status.Kind, status.Name}
+		seen[k] = true
+
+		other, ok := targets[k]
+		switch {
+		case !ok:
+			differences = append(differences, Difference{Kind: status.Kind, Name: status.Name, Type: DiffMissing,
+				SourceReplicas: status.Desired, TargetReplicas: -1})
+		case other.Desired != status.Desired:
+			differences = append(differences, Difference{Kind: status.Kind, Name: status.Name, Type: DiffMismatch,
+				SourceReplicas: status.Desired, TargetReplicas: other.Desired})
+		}
+	}
+
+	for _, status := range target {
+		if !seen[key{status.Kind, status.Name}] {
+			differences = append(differences, Difference{Kind: status.Kind, Name: status.Name, Type: DiffExtra,
+				SourceReplicas: -1, TargetReplicas: status.Desired})
+		}
+	}
+
+	sort.Slice(differences, func(i, j int) bool {
+		if differences[i].Kind != differences[j].Kind {
+			return differences[i].Kind < differences[j].Kind
+		}
+		return differences[i].Name < differences[j].Name
+	})
+
+	return differences
+}
@@ -0,0 +1,41 @@
+package scale
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	source := []WorkloadStatus{
+		{Kind: "deployment", Namespace: "production", ReplicaStatus: ReplicaStatus{Name: "api", Desired: 3}},
+		{Kind: "deployment", Namespace: "production", ReplicaStatus: ReplicaStatus{Name: "web", Desired: 2}},
+		{Kind: "deployment", Namespace: "production", ReplicaStatus: ReplicaStatus{Name: "worker", Desired: 1}},
+		{Kind: "statefulset", Namespace: "production", ReplicaStatus: ReplicaStatus{Name: "api", Desired: 1}},
+	}
+	target := []WorkloadStatus{
+		{Kind: "deployment", Namespace: "staging", ReplicaStatus: ReplicaStatus{Name: "api", Desired: 1}},
+		{Kind: "deployment", Namespace: "staging", ReplicaStatus: ReplicaStatus{Name: "web", Desired: 2}},
+		{Kind: "deployment", Namespace: "staging", ReplicaStatus: ReplicaStatus{Name: "preview", Desired: 1}},
+	}
+
+	expected := []Difference{
+		{Kind: "deployment", Name: "api", Type: DiffMismatch, SourceReplicas: 3, TargetReplicas: 1},
+		{Kind: "deployment", Name: "preview", Type: DiffExtra, SourceReplicas: -1, TargetReplicas: 1},
+		{Kind: "deployment", Name: "worker", Type: DiffMissing, SourceReplicas: 1, TargetReplicas: -1},
+		{Kind: "statefulset", Name: "api", Type: DiffMissing, SourceReplicas: 1, TargetReplicas: -1},
+	}
+
+	if differences := Diff(source, target); !reflect.DeepEqual(differences, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, differences)
+	}
+}
+
+func TestDiffNoDifferences(t *testing.T) {
+	statuses := []WorkloadStatus{
+		{Kind: "deployment", Namespace: "production", ReplicaStatus: ReplicaStatus{Name: "api", Desired: 3, Ready: 3}},
+	}
+
+	if differences := Diff(statuses, statuses); len(differences) != 0 {
+		t.Errorf("Expected no differences, got %+v", differences)
+	}
+}
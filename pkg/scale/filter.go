@@ -0,0 +1,171 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Validate checks that the patterns and regular expression in the options are
+// well-formed and that no conflicting options are set
+func (o Options) Validate() error {
+	for _, patterns := range [][]string{o.ExcludeNamespaces, o.ExcludeNames} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+			}
+		}
+	}
+
+	if o.NameRegex != "" {
+		if _, err := regexp.Compile(o.NameRegex); err != nil {
+			return fmt.Errorf("invalid name regex %q: %v", o.NameRegex, err)
+		}
+	}
+
+	if o.IgnorePDB && o.AdjustPDB {
+		return fmt.Errorf("IgnorePDB and AdjustPDB are mutually exclusive")
+	}
+
+	gitOpsModes := 0
+	for _, set := range []bool{o.SkipGitOps, o.SuspendGitOps, o.ResumeGitOps} {
+		if set {
+			gitOpsModes++
+		}
+	}
+	if gitOpsModes > 1 {
+		return fmt.Errorf("SkipGitOps, SuspendGitOps and ResumeGitOps are mutually exclusive")
+	}
+
+	if o.HPAMode != "" && !slices.Contains(HPAModes, o.HPAMode) {
+		return fmt.Errorf("invalid HPA mode %q, must be one of %v", o.HPAMode, HPAModes)
+	}
+
+	for _, bound := range []string{o.MinReplicas, o.MaxReplicas} {
+		if bound == "" {
+			continue
+		}
+		if _, err := resolveBound(bound, 0); err != nil {
+			return err
+		}
+	}
+	if o.RestoreBounds && (o.MinReplicas != "" || o.MaxReplicas != "") {
+		return fmt.Errorf("RestoreBounds cannot be combined with MinReplicas or MaxReplicas")
+	}
+	if o.Resume && (o.RestoreBounds || o.MinReplicas != "" || o.MaxReplicas != "") {
+		return fmt.Errorf("Resume cannot be combined with bounds")
+	}
+	if o.Suspend && (o.Resume || o.RestoreBounds || o.MinReplicas != "" || o.MaxReplicas != "") {
+		return fmt.Errorf("Suspend cannot be combined with Resume or bounds")
+	}
+	if o.Completions && (o.Suspend || o.Resume || o.RestoreBounds || o.MinReplicas != "" || o.MaxReplicas != "") {
+		return fmt.Errorf("Completions cannot be combined with Suspend, Resume or bounds")
+	}
+	if o.ActiveJobs != "" && !slices.Contains(ActiveJobsPolicies, o.ActiveJobs) {
+		return fmt.Errorf("invalid active jobs policy %q, must be one of %v", o.ActiveJobs, ActiveJobsPolicies)
+	}
+	return nil
+}
+
+// ValidatePatterns checks that names and namespaces given as glob patterns are well-formed
+func ValidatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// isPattern reports whether a name contains glob metacharacters
+func isPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// ResolveNamespaces returns the namespaces to operate on, expanding glob patterns
+// such as team-* against the namespaces in the cluster. Literal names are kept
+// as given, and the default namespace is used when none are given.
+func (s *Scaler) ResolveNamespaces(ctx context.Context, namespaces []string) ([]string, error) {
+	namespaces = s.Namespaces(namespaces)
+
+	hasPattern := false
+	for _, ns := range namespaces {
+		hasPattern = hasPattern || isPattern(ns)
+	}
+	if !hasPattern {
+		return namespaces, nil
+	}
+
+	existing, err := s.ListNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return expandPatterns(namespaces, existing), nil
+}
+
+// expandPatterns replaces glob patterns with the matching candidates, keeping
+// literal names as given and dropping duplicates
+func expandPatterns(patterns, candidates []string) []string {
+	var expanded []string
+	seen := map[string]bool{}
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			expanded = append(expanded, name)
+		}
+	}
+
+	for _, pattern := range patterns {
+		if !isPattern(pattern) {
+			add(pattern)
+			continue
+		}
+
+		for _, candidate := range candidates {
+			if ok, _ := path.Match(pattern, candidate); ok {
+				add(candidate)
+			}
+		}
+	}
+
+	return expanded
+}
+
+// nameSelected reports whether a name matches Options.NameRegex, if set
+func (s *Scaler) nameSelected(name string) bool {
+	return s.nameRegex == nil || s.nameRegex.MatchString(name)
+}
+
+// matchingPattern returns the first glob pattern matching the value, or an empty string.
+// Malformed patterns never match; Options.Validate reports them.
+func matchingPattern(patterns []string, value string) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// namespaceExcluded returns the reason a namespace is excluded, or an empty string
+func (s *Scaler) namespaceExcluded(namespace string) string {
+	if pattern, ok := matchingPattern(s.opts.ExcludeNamespaces, namespace); ok {
+		return fmt.Sprintf("namespace matches exclude pattern %q", pattern)
+	}
+	return ""
+}
+
+// excluded returns the reason a resource is excluded, or an empty string
+func (s *Scaler) excluded(namespace, name string) string {
+	if reason := s.namespaceExcluded(namespace); reason != "" {
+		return reason
+	}
+	if pattern, ok := matchingPattern(s.opts.ExcludeNames, name); ok {
+		return fmt.Sprintf("name matches exclude pattern %q", pattern)
+	}
+	return ""
+}
@@ -0,0 +1,310 @@
+package scale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Labels and annotations GitOps tools put on the resources they reconcile
+const (
+	ArgoCDInstanceLabel        = "argocd.argoproj.io/instance"
+	ArgoCDTrackingIDAnnotation = "argocd.argoproj.io/tracking-id"
+	FluxKustomizationLabel     = "kustomize.toolkit.fluxcd.io/name"
+	FluxKustomizationNSLabel   = "kustomize.toolkit.fluxcd.io/namespace"
+	FluxHelmReleaseLabel       = "helm.toolkit.fluxcd.io/name"
+	FluxHelmReleaseNSLabel     = "helm.toolkit.fluxcd.io/namespace"
+)
+
+// Annotations recording the state of GitOps objects changed by Options.SuspendGitOps,
+// so that it can be restored by Options.ResumeGitOps or once the resources are
+// scaled back
+const (
+	// SuspendedGitOpsAnnotation marks a Flux object suspended by mscale
+	SuspendedGitOpsAnnotation = "mscale.io/suspended-gitops"
+	// OriginalAutomatedSyncAnnotation holds the automated sync policy of an Argo CD
+	// Application disabled by mscale, as JSON
+	OriginalAutomatedSyncAnnotation = "mscale.io/original-automated-sync"
+	// OriginalReplicasAnnotation holds the replicas of the resources scaled while
+	// mscale suspended their owner, by kind/namespace/name, as JSON
+	OriginalReplicasAnnotation = "mscale.io/original-replicas"
+)
+
+// DefaultArgoCDNamespace is the namespace Argo CD Applications are looked up in
+// when Options.ArgoCDNamespace is not set
+const DefaultArgoCDNamespace = "argocd"
+
+// GitOps object resources
+var (
+	ArgoCDApplicationGVR = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+	FluxKustomizationGVR = schema.GroupVersionResource{Group: "kustomize.toolkit.fluxcd.io", Version: "v1", Resource: "kustomizations"}
+	FluxHelmReleaseGVR   = schema.GroupVersionResource{Group: "helm.toolkit.fluxcd.io", Version: "v2", Resource: "helmreleases"}
+)
+
+// GitOpsOwner is the GitOps object reconciling a resource
+type GitOpsOwner struct {
+	// Kind is Application, Kustomization or HelmRelease
+	Kind      string
+	Namespace string
+	Name      string
+	gvr       schema.GroupVersionResource
+}
+
+// String names the owner, e.g. Kustomization flux-system/apps
+func (o GitOpsOwner) String() string {
+	return fmt.Sprintf("%s %s/%s", o.Kind, o.Namespace, o.Name)
+}
+
+// gitOpsOwner detects the GitOps object reconciling a resource from its labels
+// and annotations
+func (s *Scaler) gitOpsOwner(metadata metav1.ObjectMeta) (GitOpsOwner, bool) {
+	argoNamespace := s.opts.ArgoCDNamespace
+	if argoNamespace == "" {
+		argoNamespace = DefaultArgoCDNamespace
+	}
+
+	// Argo CD uses namespace_name for Applications outside its own namespace
+	argoApplication := func(instance string) GitOpsOwner {
+		owner := GitOpsOwner{Kind: "Application", Namespace: argoNamespace, Name: instance, gvr: ArgoCDApplicationGVR}
+		if namespace, name, ok := strings.Cut(instance, "_"); ok {
+			owner.Namespace, owner.Name = namespace, name
+		}
+		return owner
+	}
+
+	// Flux objects without a namespace label are looked up in the resource's namespace
+	fluxNamespace := func(label string) string {
+		if namespace := metadata.Labels[label]; namespace != "" {
+			return namespace
+		}
+		return metadata.Namespace
+	}
+
+	switch {
+	case metadata.Labels[FluxKustomizationLabel] != "":
+		return GitOpsOwner{Kind: "Kustomization", Namespace: fluxNamespace(FluxKustomizationNSLabel),
+			Name: metadata.Labels[FluxKustomizationLabel], gvr: FluxKustomizationGVR}, true
+	case metadata.Labels[FluxHelmReleaseLabel] != "":
+		return GitOpsOwner{Kind: "HelmRelease", Namespace: fluxNamespace(FluxHelmReleaseNSLabel),
+			Name: metadata.Labels[FluxHelmReleaseLabel], gvr: FluxHelmReleaseGVR}, true
+	case metadata.Annotations[ArgoCDTrackingIDAnnotation] != "":
+		instance, _, _ := strings.Cut(metadata.Annotations[ArgoCDTrackingIDAnnotation], ":")
+		return argoApplication(instance), true
+	case metadata.Labels[ArgoCDInstanceLabel] != "":
+		return argoApplication(metadata.Labels[ArgoCDInstanceLabel]), true
+	}
+	return GitOpsOwner{}, false
+}
+
+// resourceGitOpsOwner returns the GitOps owner of a resource, if any
+func (s *Scaler) resourceGitOpsOwner(ctx context.Context, kind KindScaler, namespace, name string) (GitOpsOwner, bool) {
+	metadataKind, ok := kind.(MetadataKind)
+	if !ok {
+		return GitOpsOwner{}, false
+	}
+
+	metadata, err := metadataKind.Metadata(ctx, s.clients, namespace, name)
+	if err != nil {
+		return GitOpsOwner{}, false
+	}
+	return s.gitOpsOwner(metadata)
+}
+
+// suspendGitOps stops the owner from reverting the scaled resource: Flux objects
+// are suspended and Argo CD automated sync is disabled. The previous replicas of
+// the resource are recorded on the owner, so that releaseGitOps resumes it once
+// they are restored. Owners already suspended by someone else are left alone. It
+// returns a description of what was done.
+func (s *Scaler) suspendGitOps(ctx context.Context, owner GitOpsOwner, resource string, replicas int) (string, error) {
+	obj, err := s.getGitOpsOwner(ctx, owner)
+	if err != nil {
+		return "", err
+	}
+	recorded, err := gitOpsReplicas(obj)
+	if err != nil {
+		return "", fmt.Errorf("error reading replicas recorded on %s: %v", owner, err)
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations[SuspendedGitOpsAnnotation] != "" || annotations[OriginalAutomatedSyncAnnotation] != "" {
+		if _, ok := recorded[resource]; !ok {
+			recorded[resource] = replicas
+			if err := s.recordGitOpsReplicas(ctx, owner, recorded); err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("%s is suspended", owner), nil
+	}
+
+	original, err := json.Marshal(map[string]int{resource: replicas})
+	if err != nil {
+		return "", fmt.Errorf("error encoding replicas for %s: %v", owner, err)
+	}
+
+	var patch map[string]any
+	var done string
+	if owner.Kind == "Application" {
+		automated, found, _ := unstructured.NestedMap(obj.Object, "spec", "syncPolicy", "automated")
+		if !found {
+			return fmt.Sprintf("%s has no automated sync", owner), nil
+		}
+
+		sync, err := json.Marshal(automated)
+		if err != nil {
+			return "", fmt.Errorf("error encoding sync policy of %s: %v", owner, err)
+		}
+		patch = map[string]any{
+			"metadata": map[string]any{"annotations": map[string]any{
+				OriginalAutomatedSyncAnnotation: string(sync),
+				OriginalReplicasAnnotation:      string(original),
+			}},
+			"spec": map[string]any{"syncPolicy": map[string]any{"automated": nil}},
+		}
+		done = fmt.Sprintf("disabled automated sync of %s", owner)
+	} else {
+		if suspended, _, _ := unstructured.NestedBool(obj.Object, "spec", "suspend"); suspended {
+			return fmt.Sprintf("%s is already suspended", owner), nil
+		}
+		patch = map[string]any{
+			"metadata": map[string]any{"annotations": map[string]any{
+				SuspendedGitOpsAnnotation:  "true",
+				OriginalReplicasAnnotation: string(original),
+			}},
+			"spec": map[string]any{"suspend": true},
+		}
+		done = fmt.Sprintf("suspended %s", owner)
+	}
+
+	if err := s.patchGitOpsOwner(ctx, owner, patch); err != nil {
+		return "", err
+	}
+	return done, nil
+}
+
+// releaseGitOps forgets the replicas recorded for a resource scaled back to them,
+// and resumes the owner once no other resource is left scaled. It returns a
+// description of what was done, empty if nothing.
+func (s *Scaler) releaseGitOps(ctx context.Context, owner GitOpsOwner, resource string, replicas int) (string, error) {
+	obj, err := s.getGitOpsOwner(ctx, owner)
+	if err != nil {
+		return "", err
+	}
+	recorded, err := gitOpsReplicas(obj)
+	if err != nil {
+		return "", fmt.Errorf("error reading replicas recorded on %s: %v", owner, err)
+	}
+
+	original, ok := recorded[resource]
+	if !ok || original != replicas {
+		return "", nil
+	}
+	delete(recorded, resource)
+	if len(recorded) > 0 {
+		if err := s.recordGitOpsReplicas(ctx, owner, recorded); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s stays suspended until %d more resources are scaled back", owner, len(recorded)), nil
+	}
+	return s.resumeGitOps(ctx, owner)
+}
+
+// resumeGitOps undoes suspendGitOps, leaving owners suspended by someone else
+// alone. It returns a description of what was done, empty if nothing.
+func (s *Scaler) resumeGitOps(ctx context.Context, owner GitOpsOwner) (string, error) {
+	obj, err := s.getGitOpsOwner(ctx, owner)
+	if err != nil {
+		return "", err
+	}
+
+	annotations := obj.GetAnnotations()
+	switch {
+	case annotations[OriginalAutomatedSyncAnnotation] != "":
+		var automated map[string]any
+		if err := json.Unmarshal([]byte(annotations[OriginalAutomatedSyncAnnotation]), &automated); err != nil {
+			return "", fmt.Errorf("error decoding original sync policy of %s: %v", owner, err)
+		}
+		err = s.patchGitOpsOwner(ctx, owner, map[string]any{
+			"metadata": map[string]any{"annotations": map[string]any{
+				OriginalAutomatedSyncAnnotation: nil,
+				OriginalReplicasAnnotation:      nil,
+			}},
+			"spec": map[string]any{"syncPolicy": map[string]any{"automated": automated}},
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("restored automated sync of %s", owner), nil
+
+	case annotations[SuspendedGitOpsAnnotation] != "":
+		err = s.patchGitOpsOwner(ctx, owner, map[string]any{
+			"metadata": map[string]any{"annotations": map[string]any{
+				SuspendedGitOpsAnnotation:  nil,
+				OriginalReplicasAnnotation: nil,
+			}},
+			"spec": map[string]any{"suspend": false},
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("resumed %s", owner), nil
+	}
+	return "", nil
+}
+
+// gitOpsReplicas returns the replicas recorded on a GitOps object by suspendGitOps
+func gitOpsReplicas(obj *unstructured.Unstructured) (map[string]int, error) {
+	recorded := map[string]int{}
+	value, ok := obj.GetAnnotations()[OriginalReplicasAnnotation]
+	if !ok {
+		return recorded, nil
+	}
+	if err := json.Unmarshal([]byte(value), &recorded); err != nil {
+		return nil, err
+	}
+	return recorded, nil
+}
+
+// recordGitOpsReplicas replaces the replicas recorded on a GitOps object
+func (s *Scaler) recordGitOpsReplicas(ctx context.Context, owner GitOpsOwner, recorded map[string]int) error {
+	value, err := json.Marshal(recorded)
+	if err != nil {
+		return fmt.Errorf("error encoding replicas for %s: %v", owner, err)
+	}
+	return s.patchGitOpsOwner(ctx, owner, map[string]any{
+		"metadata": map[string]any{"annotations": map[string]any{OriginalReplicasAnnotation: string(value)}},
+	})
+}
+
+// getGitOpsOwner reads the GitOps object with the dynamic client
+func (s *Scaler) getGitOpsOwner(ctx context.Context, owner GitOpsOwner) (*unstructured.Unstructured, error) {
+	if s.clients.Dynamic == nil {
+		return nil, fmt.Errorf("a dynamic client is required to change %s", owner)
+	}
+
+	obj, err := s.clients.Dynamic.Resource(owner.gvr).Namespace(owner.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s: %v", owner, err)
+	}
+	return obj, nil
+}
+
+// patchGitOpsOwner applies a JSON merge patch to the GitOps object
+func (s *Scaler) patchGitOpsOwner(ctx context.Context, owner GitOpsOwner, patch map[string]any) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("error encoding patch for %s: %v", owner, err)
+	}
+
+	_, err = s.clients.Dynamic.Resource(owner.gvr).Namespace(owner.Namespace).Patch(ctx, owner.Name, types.MergePatchType, data,
+		metav1.PatchOptions{FieldManager: FieldManager})
+	if err != nil {
+		return fmt.Errorf("error patching %s: %v", owner, err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package scale
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newGitOpsClients returns fake clients with a deployment reconciled by a Flux
+// Kustomization and one reconciled by an Argo CD Application with automated sync
+func newGitOpsClients() (*fake.Clientset, *dynamicfake.FakeDynamicClient) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "flux-app",
+				Namespace: "default",
+				Labels:    map[string]string{FluxKustomizationLabel: "apps", FluxKustomizationNSLabel: "flux-system"},
+			},
+			Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "argo-app",
+				Namespace: "default",
+				Labels:    map[string]string{ArgoCDInstanceLabel: "guestbook"},
+			},
+			Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+	)
+
+	kustomization := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "kustomize.toolkit.fluxcd.io/v1",
+		"kind":       "Kustomization",
+		"metadata":   map[string]any{"name": "apps", "namespace": "flux-system"},
+		"spec":       map[string]any{"interval": "10m"},
+	}}
+	application := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata":   map[string]any{"name": "guestbook", "namespace": "argocd"},
+		"spec": map[string]any{
+			"syncPolicy": map[string]any{"automated": map[string]any{"prune": true, "selfHeal": true}},
+		},
+	}}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			FluxKustomizationGVR: "KustomizationList",
+			ArgoCDApplicationGVR: "ApplicationList",
+		},
+		kustomization, application)
+
+	return clientset, dynamicClient
+}
+
+func TestScaleGitOpsManagedWarnsOrSkips(t *testing.T) {
+	clientset, dynamicClient := newGitOpsClients()
+
+	result := NewScaler(clientset, dynamicClient, Options{Replicas: 0, CurrentReplicas: -1}).Scale(context.TODO(), "deployment", "flux-app", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to scale deployment: %v", result.Err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "Kustomization flux-system/apps") {
+		t.Errorf("Expected a warning about the Kustomization, got %v", result.Warnings)
+	}
+
+	result = NewScaler(clientset, dynamicClient, Options{Replicas: 0, CurrentReplicas: -1, SkipGitOps: true}).Scale(context.TODO(), "deployment", "argo-app", "default")
+	if result.Skipped != "managed by Application argocd/guestbook" {
+		t.Errorf("Expected deployment to be skipped, got %q", result.Skipped)
+	}
+}
+
+func TestScaleKeepsGitOpsSuspended(t *testing.T) {
+	clientset, dynamicClient := newGitOpsClients()
+	ctx := context.TODO()
+	kustomizations := dynamicClient.Resource(FluxKustomizationGVR).Namespace("flux-system")
+	applications := dynamicClient.Resource(ArgoCDApplicationGVR).Namespace("argocd")
+
+	scaler := NewScaler(clientset, dynamicClient, Options{Replicas: 0, CurrentReplicas: -1, SuspendGitOps: true})
+	for _, name := range []string{"flux-app", "argo-app"} {
+		if result := scaler.Scale(ctx, "deployment", name, "default"); result.Err != nil {
+			t.Fatalf("Failed to scale %s: %v", name, result.Err)
+		}
+	}
+
+	kustomization, err := kustomizations.Get(ctx, "apps", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get Kustomization: %v", err)
+	}
+	if suspended, _, _ := unstructured.NestedBool(kustomization.Object, "spec", "suspend"); !suspended {
+		t.Error("Expected Kustomization to stay suspended after scaling")
+	}
+	if recorded := kustomization.GetAnnotations()[OriginalReplicasAnnotation]; recorded != `{"deployment/default/flux-app":2}` {
+		t.Errorf("Expected the previous replicas to be recorded, got %q", recorded)
+	}
+
+	application, err := applications.Get(ctx, "guestbook", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get Application: %v", err)
+	}
+	if _, found, _ := unstructured.NestedMap(application.Object, "spec", "syncPolicy", "automated"); found {
+		t.Error("Expected automated sync to stay disabled after scaling")
+	}
+	if _, ok := application.GetAnnotations()[OriginalAutomatedSyncAnnotation]; !ok {
+		t.Error("Expected the original sync policy to be recorded")
+	}
+
+	// Scaling to other replicas keeps the owner suspended, scaling back resumes it
+	for replicas, suspended := range []bool{true, false} {
+		if result := NewScaler(clientset, dynamicClient, Options{Replicas: replicas + 1, CurrentReplicas: -1}).Scale(ctx, "deployment", "flux-app", "default"); result.Err != nil {
+			t.Fatalf("Failed to scale deployment: %v", result.Err)
+		}
+		kustomization, err = kustomizations.Get(ctx, "apps", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get Kustomization: %v", err)
+		}
+		if got, _, _ := unstructured.NestedBool(kustomization.Object, "spec", "suspend"); got != suspended {
+			t.Errorf("Expected Kustomization suspended %v at %d replicas, got %v", suspended, replicas+1, got)
+		}
+	}
+	if _, ok := kustomization.GetAnnotations()[SuspendedGitOpsAnnotation]; ok {
+		t.Error("Expected suspended annotation to be removed")
+	}
+
+	result := NewScaler(clientset, dynamicClient, Options{Replicas: 1, CurrentReplicas: -1, ResumeGitOps: true}).Scale(ctx, "deployment", "argo-app", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to scale deployment: %v", result.Err)
+	}
+	application, err = applications.Get(ctx, "guestbook", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get Application: %v", err)
+	}
+	if selfHeal, _, _ := unstructured.NestedBool(application.Object, "spec", "syncPolicy", "automated", "selfHeal"); !selfHeal {
+		t.Error("Expected automated sync to be restored by ResumeGitOps")
+	}
+}
+
+func TestScaleLeavesSuspendedGitOpsAlone(t *testing.T) {
+	clientset, dynamicClient := newGitOpsClients()
+	ctx := context.TODO()
+
+	kustomizations := dynamicClient.Resource(FluxKustomizationGVR).Namespace("flux-system")
+	kustomization, err := kustomizations.Get(ctx, "apps", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get Kustomization: %v", err)
+	}
+	unstructured.SetNestedField(kustomization.Object, true, "spec", "suspend")
+	if _, err := kustomizations.Update(ctx, kustomization, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to suspend Kustomization: %v", err)
+	}
+
+	scaler := NewScaler(clientset, dynamicClient, Options{Replicas: 0, CurrentReplicas: -1, SuspendGitOps: true})
+	if result := scaler.Scale(ctx, "deployment", "flux-app", "default"); result.Err != nil {
+		t.Fatalf("Failed to scale deployment: %v", result.Err)
+	}
+
+	kustomization, err = kustomizations.Get(ctx, "apps", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get Kustomization: %v", err)
+	}
+	if suspended, _, _ := unstructured.NestedBool(kustomization.Object, "spec", "suspend"); !suspended {
+		t.Error("Expected Kustomization suspended by someone else to stay suspended")
+	}
+}
+
+func TestGitOpsOwnerFluxNamespace(t *testing.T) {
+	scaler := NewScaler(fake.NewSimpleClientset(), nil, Options{})
+
+	owner, ok := scaler.gitOpsOwner(metav1.ObjectMeta{
+		Name:      "api",
+		Namespace: "staging",
+		Labels:    map[string]string{FluxHelmReleaseLabel: "api"},
+	})
+	if !ok || owner.String() != "HelmRelease staging/api" {
+		t.Errorf("Expected HelmRelease staging/api without a namespace label, got %v", owner)
+	}
+
+	owner, _ = scaler.gitOpsOwner(metav1.ObjectMeta{
+		Name:      "api",
+		Namespace: "staging",
+		Labels:    map[string]string{FluxHelmReleaseLabel: "api", FluxHelmReleaseNSLabel: "flux-system"},
+	})
+	if owner.String() != "HelmRelease flux-system/api" {
+		t.Errorf("Expected HelmRelease flux-system/api, got %v", owner)
+	}
+}
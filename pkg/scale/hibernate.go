@@ -0,0 +1,329 @@
+package scale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// HibernationAnnotation records on a namespace the resources scaled to zero by
+// Hibernate and their previous replicas, as JSON
+const HibernationAnnotation = "mscale.io/hibernation"
+
+// HibernateKinds are the kinds scaled to zero by Hibernate, in order. KEDA
+// ScaledObjects are paused before their targets are scaled, so that KEDA does
+// not scale them up again. CronJobs are suspended by scaling them to zero.
+// HorizontalPodAutoscalers are not scaled, but pinned by Hibernate.
+var HibernateKinds = []string{"scaledobject", "deployment", "statefulset", "cronjob"}
+
+// HibernatedResource is a resource scaled to zero or pinned by Hibernate
+type HibernatedResource struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Replicas is the replica count before hibernating, or for
+	// HorizontalPodAutoscalers the min replicas they are pinned at
+	Replicas int `json:"replicas"`
+	// Resume is set when the resource is handed back to its autoscaler on wake,
+	// such as a ScaledObject that was not paused before hibernating
+	Resume bool `json:"resume,omitempty"`
+}
+
+// Hibernate scales every resource of HibernateKinds in the namespaces to zero
+// and pins their HorizontalPodAutoscalers, recording the resources and their
+// previous replicas on the namespace with HibernationAnnotation for Wake. Only
+// resources that were hibernated successfully are recorded, so running it again
+// retries the others. Namespaces with nothing left to hibernate are skipped.
+func (s *Scaler) Hibernate(ctx context.Context, namespaces []string) ([]Result, error) {
+	namespaces, err := s.ResolveNamespaces(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	hibernator := *s
+	hibernator.opts.Replicas = 0
+	hibernator.opts.CurrentReplicas = -1
+	hibernator.opts.Resume = false
+	hibernator.opts.Suspend = false
+	hibernator.opts.Completions = false
+
+	var results []Result
+	for _, ns := range namespaces {
+		if reason := s.namespaceExcluded(ns); reason != "" {
+			results = append(results, Result{Kind: "namespace", Namespace: ns, PreviousReplicas: -1, Skipped: reason})
+			continue
+		}
+
+		state, err := s.hibernationState(ctx, ns)
+		if err != nil {
+			results = append(results, Result{Kind: "namespace", Namespace: ns, PreviousReplicas: -1, Err: err})
+			continue
+		}
+		recorded := map[Target]bool{}
+		for _, resource := range state {
+			recorded[Target{Kind: resource.Kind, Namespace: ns, Name: resource.Name}] = true
+		}
+
+		targets, resume, listed := hibernator.hibernationTargets(ctx, ns, recorded)
+		hpas, pinErr := hibernator.hibernationHPAs(ctx, ns, recorded)
+		if pinErr != nil {
+			listed = append(listed, Result{Kind: "horizontalpodautoscaler", Namespace: ns, PreviousReplicas: -1, Err: pinErr})
+		}
+		if state != nil && len(targets) == 0 && len(hpas) == 0 && len(listed) == 0 {
+			results = append(results, Result{Kind: "namespace", Namespace: ns, PreviousReplicas: -1, Skipped: "already hibernated"})
+			continue
+		}
+		results = append(results, listed...)
+
+		scaled, err := hibernator.ScaleTargets(ctx, targets)
+		results = append(results, scaled...)
+
+		// HPAs are pinned once their targets are down
+		var pinned []Result
+		if err == nil {
+			pinned = hibernator.pinHPAs(ctx, hpas)
+			results = append(results, pinned...)
+		}
+
+		for _, result := range append(scaled, pinned...) {
+			if result.Succeeded() {
+				target := Target{Kind: result.Kind, Namespace: result.Namespace, Name: result.Name}
+				state = append(state, HibernatedResource{Kind: result.Kind, Name: result.Name,
+					Replicas: result.PreviousReplicas, Resume: resume[target]})
+			}
+		}
+		if !s.opts.DryRun && len(state) > 0 {
+			if recordErr := s.setHibernationState(ctx, ns, state); recordErr != nil {
+				results = append(results, Result{Kind: "namespace", Namespace: ns, PreviousReplicas: -1, Err: recordErr})
+			}
+		}
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// hibernationTargets lists the resources of HibernateKinds in a namespace that
+// are not recorded yet, and the ScaledObjects among them to resume on wake. Kinds
+// that cannot be listed are returned as failed results.
+func (s *Scaler) hibernationTargets(ctx context.Context, namespace string, recorded map[Target]bool) ([]Target, map[Target]bool, []Result) {
+	var targets []Target
+	var failed []Result
+	resume := map[Target]bool{}
+	for _, kindName := range HibernateKinds {
+		kind, err := Lookup(kindName)
+		if err != nil {
+			failed = append(failed, Result{Kind: kindName, Namespace: namespace, PreviousReplicas: -1, Err: err})
+			continue
+		}
+
+		names, err := kind.List(ctx, s.clients, namespace)
+		if err != nil {
+			failed = append(failed, Result{Kind: kind.Name(), Namespace: namespace, PreviousReplicas: -1,
+				Err: fmt.Errorf("error listing %ss: %v", kind.Name(), err)})
+			continue
+		}
+
+		for _, name := range names {
+			target := Target{Kind: kind.Name(), Namespace: namespace, Name: name}
+			if recorded[target] || !s.nameSelected(name) {
+				continue
+			}
+			targets = append(targets, target)
+
+			if kind.Name() == "scaledobject" {
+				scaledObject, err := getScaledObject(ctx, s.clients, namespace, name)
+				if err != nil {
+					continue
+				}
+				if _, paused, _ := pausedReplicas(scaledObject); !paused {
+					resume[target] = true
+				}
+			}
+		}
+	}
+	return targets, resume, failed
+}
+
+// hibernationHPAs lists the HorizontalPodAutoscalers in a namespace to pin that
+// are not recorded yet. HPAs owned by a controller, such as those KEDA creates
+// for ScaledObjects, HPAs whose bounds mscale already changed and HPAs that
+// cannot scale beyond their min replicas are left alone.
+func (s *Scaler) hibernationHPAs(ctx context.Context, namespace string, recorded map[Target]bool) ([]Target, error) {
+	hpas, err := s.clients.Kubernetes.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing HorizontalPodAutoscalers: %v", err)
+	}
+
+	var targets []Target
+	for _, hpa := range hpas.Items {
+		target := Target{Kind: hpaKind{}.Name(), Namespace: namespace, Name: hpa.Name}
+		if recorded[target] || !s.nameSelected(hpa.Name) || metav1.GetControllerOfNoCopy(&hpa) != nil {
+			continue
+		}
+		current, original, err := hpaBounds(&hpa)
+		if err != nil {
+			return targets, err
+		}
+		if original == nil && current.Min > 0 && current.Min < current.Max {
+			targets = append(targets, target)
+		}
+	}
+	return targets, nil
+}
+
+// pinHPAs pins HorizontalPodAutoscalers at their min replicas, recording their
+// bounds on them, so that a workload started while its namespace is hibernated,
+// such as by hand, is not scaled up by its HPA
+func (s *Scaler) pinHPAs(ctx context.Context, hpas []Target) []Result {
+	var results []Result
+	for _, hpa := range hpas {
+		minReplicas, err := hpaKind{}.GetReplicas(ctx, s.clients, hpa.Namespace, hpa.Name)
+		if err != nil {
+			results = append(results, Result{Kind: hpa.Kind, Namespace: hpa.Namespace, Name: hpa.Name, PreviousReplicas: -1,
+				Err: fmt.Errorf("error getting %s: %v", hpa.Kind, err)})
+			continue
+		}
+
+		pinner := *s
+		pinner.opts.MinReplicas = ""
+		pinner.opts.MaxReplicas = strconv.Itoa(minReplicas)
+		results = append(results, pinner.ScaleTo(ctx, hpa.Kind, hpa.Name, hpa.Namespace, minReplicas))
+	}
+	return results
+}
+
+// Wake restores the resources recorded by Hibernate in the namespaces: the bounds
+// of pinned HorizontalPodAutoscalers first, so that they take over their targets
+// once started, then the previous replicas in the order given by the ordering
+// annotations, and finally hands resources that were not paused back to their
+// autoscaler. GitOps owners suspended by mscale are resumed. The record is removed once every resource is restored. Namespaces
+// that are not hibernated are skipped.
+func (s *Scaler) Wake(ctx context.Context, namespaces []string) ([]Result, error) {
+	namespaces, err := s.ResolveNamespaces(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	waker := *s
+	waker.opts.CurrentReplicas = -1
+	waker.opts.Resume = false
+	waker.opts.Suspend = false
+	waker.opts.Completions = false
+	waker.opts.SuspendGitOps = false
+	waker.opts.ResumeGitOps = !s.opts.SkipGitOps
+
+	resumer := waker
+	resumer.opts.Resume = true
+
+	unpinner := waker
+	unpinner.opts.RestoreBounds = true
+
+	var results []Result
+	for _, ns := range namespaces {
+		if reason := s.namespaceExcluded(ns); reason != "" {
+			results = append(results, Result{Kind: "namespace", Namespace: ns, PreviousReplicas: -1, Skipped: reason})
+			continue
+		}
+
+		state, err := s.hibernationState(ctx, ns)
+		if err != nil {
+			results = append(results, Result{Kind: "namespace", Namespace: ns, PreviousReplicas: -1, Err: err})
+			continue
+		}
+		if state == nil {
+			results = append(results, Result{Kind: "namespace", Namespace: ns, PreviousReplicas: -1, Skipped: "not hibernated"})
+			continue
+		}
+
+		var restored []Result
+		var targets, resumes []Target
+		replicas := map[Target]int{}
+		for _, resource := range state {
+			target := Target{Kind: resource.Kind, Namespace: ns, Name: resource.Name}
+			switch {
+			case resource.Kind == hpaKind{}.Name():
+				restored = append(restored, unpinner.ScaleTo(ctx, resource.Kind, resource.Name, ns, -1))
+			case resource.Resume:
+				resumes = append(resumes, target)
+			default:
+				targets = append(targets, target)
+				replicas[target] = resource.Replicas
+			}
+		}
+
+		scaled, err := waker.ScaleTargetsTo(ctx, targets, replicas)
+		restored = append(restored, scaled...)
+		if err == nil {
+			var resumed []Result
+			resumed, err = resumer.ScaleTargets(ctx, resumes)
+			restored = append(restored, resumed...)
+		}
+		results = append(results, restored...)
+		if err != nil {
+			return results, err
+		}
+
+		failed := false
+		for _, result := range restored {
+			failed = failed || result.Failed()
+		}
+		if !failed && !s.opts.DryRun {
+			if err := s.setHibernationState(ctx, ns, nil); err != nil {
+				results = append(results, Result{Kind: "namespace", Namespace: ns, PreviousReplicas: -1, Err: err})
+			}
+		}
+	}
+	return results, nil
+}
+
+// hibernationState returns the resources recorded on a hibernated namespace,
+// or nil if it is not hibernated
+func (s *Scaler) hibernationState(ctx context.Context, namespace string) ([]HibernatedResource, error) {
+	ns, err := s.clients.Kubernetes.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting namespace: %v", err)
+	}
+
+	value, ok := ns.Annotations[HibernationAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	state := []HibernatedResource{}
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation on namespace %s: %v", HibernationAnnotation, namespace, err)
+	}
+	return state, nil
+}
+
+// setHibernationState records the hibernated resources on a namespace, or
+// removes the record when state is nil
+func (s *Scaler) setHibernationState(ctx context.Context, namespace string, state []HibernatedResource) error {
+	var value any
+	if state != nil {
+		encoded, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		value = string(encoded)
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{"annotations": map[string]any{HibernationAnnotation: value}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.clients.Kubernetes.CoreV1().Namespaces().Patch(ctx, namespace, types.MergePatchType, patch,
+		metav1.PatchOptions{FieldManager: FieldManager})
+	if err != nil {
+		return fmt.Errorf("error recording hibernation on namespace: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package scale
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newPreviewClients returns fake clients with a preview namespace running a web
+// deployment that depends on a db statefulset and is scaled by an HPA, a worker
+// deployment scaled by a ScaledObject through the HPA KEDA creates for it and a
+// cleanup cronjob. As there is no controller to update their status, db and
+// worker report as ready once restored.
+func newPreviewClients() (*fake.Clientset, *dynamicfake.FakeDynamicClient) {
+	controller := true
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "preview"}},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "preview",
+				Annotations: map[string]string{DependsOnAnnotation: "statefulset/db"}},
+			Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "preview"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+			Status:     appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "preview"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(1)},
+			Status:     appsv1.StatefulSetStatus{Replicas: 1, ReadyReplicas: 1},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "cleanup", Namespace: "preview"},
+			Spec:       batchv1.CronJobSpec{Schedule: "0 * * * *"},
+		},
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "preview"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+				MinReplicas:    int32Ptr(2),
+				MaxReplicas:    5,
+			},
+		},
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "keda-hpa-worker", Namespace: "preview",
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "keda.sh/v1alpha1", Kind: "ScaledObject", Name: "worker", Controller: &controller}}},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "worker"},
+				MinReplicas:    int32Ptr(1),
+				MaxReplicas:    10,
+			},
+		},
+	)
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{ScaledObjectGVR: "ScaledObjectList"},
+		newScaledObject("preview"))
+	dynamicClient.PrependReactor("patch", "scaledobjects", applyAsMergePatch(dynamicClient.Tracker()))
+
+	return clientset, dynamicClient
+}
+
+func TestHibernateAndWake(t *testing.T) {
+	defer func(interval time.Duration) { readyPollInterval = interval }(readyPollInterval)
+	readyPollInterval = 10 * time.Millisecond
+
+	clientset, dynamicClient := newPreviewClients()
+	ctx := context.TODO()
+
+	results, err := NewScaler(clientset, dynamicClient, Options{CurrentReplicas: -1}).Hibernate(ctx, []string{"preview"})
+	if err != nil {
+		t.Fatalf("Failed to hibernate: %v", err)
+	}
+	if len(results) != 6 {
+		t.Fatalf("Expected 6 results, got %+v", results)
+	}
+	for _, result := range results {
+		if result.Kind == "horizontalpodautoscaler" {
+			if !result.Succeeded() || result.Name != "web" || result.Replicas != 2 {
+				t.Errorf("Expected only the web HPA to be pinned at 2 replicas, got %+v", result)
+			}
+			continue
+		}
+		if !result.Succeeded() || result.Replicas != 0 {
+			t.Errorf("Expected %s %s to be scaled to 0, got %+v", result.Kind, result.Name, result)
+		}
+	}
+	assertHPABounds(t, clientset, "web", 2, 2)
+	assertHPABounds(t, clientset, "keda-hpa-worker", 1, 10)
+
+	state, err := NewScaler(clientset, dynamicClient, Options{}).hibernationState(ctx, "preview")
+	if err != nil || len(state) != 6 {
+		t.Fatalf("Expected 6 recorded resources, got %v: %v", state, err)
+	}
+
+	cronjob, err := clientset.BatchV1().CronJobs("preview").Get(ctx, "cleanup", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get cronjob: %v", err)
+	}
+	if cronjob.Spec.Suspend == nil || !*cronjob.Spec.Suspend {
+		t.Error("Expected cronjob to be suspended")
+	}
+
+	results, err = NewScaler(clientset, dynamicClient, Options{CurrentReplicas: -1}).Hibernate(ctx, []string{"preview"})
+	if err != nil || len(results) != 1 || results[0].Skipped != "already hibernated" {
+		t.Errorf("Expected hibernated namespace to be skipped, got %+v: %v", results, err)
+	}
+
+	results, err = NewScaler(clientset, dynamicClient, Options{CurrentReplicas: -1, WaitTimeout: time.Second}).Wake(ctx, []string{"preview"})
+	if err != nil {
+		t.Fatalf("Failed to wake: %v", err)
+	}
+
+	// The HPA is unpinned first, the statefulset is restored before the deployment
+	// depending on it, and the ScaledObject, which was not paused, is resumed last
+	var order []string
+	for _, result := range results {
+		if !result.Succeeded() {
+			t.Errorf("Expected %s %s to be restored, got %v", result.Kind, result.Name, result.Err)
+		}
+		order = append(order, result.Kind+"/"+result.Name)
+	}
+	if order[0] != "horizontalpodautoscaler/web" {
+		t.Errorf("Expected the HPA to be unpinned first, got %v", order)
+	}
+	if slices.Index(order, "statefulset/db") > slices.Index(order, "deployment/web") {
+		t.Errorf("Expected db to be restored before web, got %v", order)
+	}
+	if last := order[len(order)-1]; last != "scaledobject/worker" || !results[len(results)-1].Resumed {
+		t.Errorf("Expected the scaledobject to be resumed last, got %v", order)
+	}
+
+	for name, replicas := range map[string]int32{"web": 2, "worker": 3} {
+		deployment, err := clientset.AppsV1().Deployments("preview").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment: %v", err)
+		}
+		if *deployment.Spec.Replicas != replicas {
+			t.Errorf("Expected %s to be restored to %d replicas, got %d", name, replicas, *deployment.Spec.Replicas)
+		}
+	}
+
+	assertHPABounds(t, clientset, "web", 2, 5)
+
+	scaledObject, err := dynamicClient.Resource(ScaledObjectGVR).Namespace("preview").Get(ctx, "worker", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get scaledobject: %v", err)
+	}
+	if _, ok := scaledObject.GetAnnotations()[KEDAPausedReplicasAnnotation]; ok {
+		t.Error("Expected scaledobject to be resumed")
+	}
+
+	namespace, err := clientset.CoreV1().Namespaces().Get(ctx, "preview", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get namespace: %v", err)
+	}
+	if _, ok := namespace.Annotations[HibernationAnnotation]; ok {
+		t.Error("Expected hibernation record to be removed")
+	}
+}
+
+// assertHPABounds fails the test unless the HPA in the preview namespace has the bounds
+func assertHPABounds(t *testing.T, clientset *fake.Clientset, name string, minReplicas, maxReplicas int32) {
+	t.Helper()
+	hpa, err := clientset.AutoscalingV2().HorizontalPodAutoscalers("preview").Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get HPA: %v", err)
+	}
+	if *hpa.Spec.MinReplicas != minReplicas || hpa.Spec.MaxReplicas != maxReplicas {
+		t.Errorf("Expected HPA %s to have bounds %d-%d, got %d-%d", name, minReplicas, maxReplicas, *hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+	}
+}
+
+func TestHibernateRetriesFailedResources(t *testing.T) {
+	clientset, dynamicClient := newPreviewClients()
+	ctx := context.TODO()
+
+	failing := true
+	clientset.PrependReactor("patch", "statefulsets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return failing, nil, errors.New("unavailable")
+	})
+
+	results, err := NewScaler(clientset, dynamicClient, Options{CurrentReplicas: -1}).Hibernate(ctx, []string{"preview"})
+	if err != nil {
+		t.Fatalf("Failed to hibernate: %v", err)
+	}
+	var failed []string
+	for _, result := range results {
+		if result.Failed() {
+			failed = append(failed, result.Kind+"/"+result.Name)
+		}
+	}
+	if !slices.Equal(failed, []string{"statefulset/db"}) {
+		t.Fatalf("Expected only db to fail, got %+v", results)
+	}
+
+	state, err := NewScaler(clientset, dynamicClient, Options{}).hibernationState(ctx, "preview")
+	if err != nil || len(state) != 5 {
+		t.Fatalf("Expected the 5 hibernated resources to be recorded, got %v: %v", state, err)
+	}
+
+	// Running it again only retries the resource that failed
+	failing = false
+	results, err = NewScaler(clientset, dynamicClient, Options{CurrentReplicas: -1}).Hibernate(ctx, []string{"preview"})
+	if err != nil || len(results) != 1 || results[0].Name != "db" || !results[0].Succeeded() {
+		t.Fatalf("Expected db to be hibernated, got %+v: %v", results, err)
+	}
+
+	state, err = NewScaler(clientset, dynamicClient, Options{}).hibernationState(ctx, "preview")
+	if err != nil || len(state) != 6 {
+		t.Fatalf("Expected 6 recorded resources, got %v: %v", state, err)
+	}
+	for _, resource := range state {
+		if resource.Name == "db" && resource.Replicas != 1 {
+			t.Errorf("Expected db to be recorded with 1 replica, got %+v", resource)
+		}
+	}
+}
+
+func TestHibernateDryRun(t *testing.T) {
+	clientset, dynamicClient := newPreviewClients()
+	ctx := context.TODO()
+
+	results, err := NewScaler(clientset, dynamicClient, Options{CurrentReplicas: -1, DryRun: true}).Hibernate(ctx, []string{"preview"})
+	if err != nil || len(results) != 6 {
+		t.Fatalf("Expected 6 results, got %+v: %v", results, err)
+	}
+
+	namespace, err := clientset.CoreV1().Namespaces().Get(ctx, "preview", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get namespace: %v", err)
+	}
+	if _, ok := namespace.Annotations[HibernationAnnotation]; ok {
+		t.Error("Expected no hibernation record in a dry run")
+	}
+}
+
+func TestWakeNotHibernated(t *testing.T) {
+	clientset, dynamicClient := newPreviewClients()
+
+	results, err := NewScaler(clientset, dynamicClient, Options{CurrentReplicas: -1}).Wake(context.TODO(), []string{"preview"})
+	if err != nil || len(results) != 1 || results[0].Skipped != "not hibernated" {
+		t.Errorf("Expected namespace that is not hibernated to be skipped, got %+v: %v", results, err)
+	}
+}
@@ -0,0 +1,132 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HPAMode is how resources targeted by a HorizontalPodAutoscaler are scaled
+type HPAMode string
+
+// HPA modes
+const (
+	// HPAWarn scales the resource and warns that the HPA may change its replicas
+	HPAWarn HPAMode = "warn"
+	// HPASkip leaves the resource alone
+	HPASkip HPAMode = "skip"
+	// HPAAdjust sets the HPA's minReplicas to the replicas, raising maxReplicas
+	// when needed, and scales the resource
+	HPAAdjust HPAMode = "adjust"
+	// HPAPin sets the HPA's min and max replicas to the replicas and scales the resource
+	HPAPin HPAMode = "pin"
+	// HPAUnpin restores the bounds recorded by HPAAdjust or HPAPin and scales the resource
+	HPAUnpin HPAMode = "unpin"
+)
+
+// HPAModes lists the valid HPA modes
+var HPAModes = []HPAMode{HPAWarn, HPASkip, HPAAdjust, HPAPin, HPAUnpin}
+
+// hpaTargetKinds maps the kinds HorizontalPodAutoscalers can target to their scaleTargetRef kind
+var hpaTargetKinds = map[string]string{
+	"deployment":            "Deployment",
+	"statefulset":           "StatefulSet",
+	"replicaset":            "ReplicaSet",
+	"replicationcontroller": "ReplicationController",
+}
+
+// targetingHPAs returns the HorizontalPodAutoscalers whose scaleTargetRef points
+// at a resource, or nil for kinds HPAs cannot target
+func (s *Scaler) targetingHPAs(ctx context.Context, kind KindScaler, namespace, name string) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
+	targetKind, ok := hpaTargetKinds[kind.Name()]
+	if !ok {
+		return nil, nil
+	}
+
+	hpas, err := s.clients.Kubernetes.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing HorizontalPodAutoscalers: %v", err)
+	}
+
+	var targeting []autoscalingv2.HorizontalPodAutoscaler
+	for _, hpa := range hpas.Items {
+		if hpa.Spec.ScaleTargetRef.Kind == targetKind && hpa.Spec.ScaleTargetRef.Name == name {
+			targeting = append(targeting, hpa)
+		}
+	}
+	return targeting, nil
+}
+
+// hpaBounds returns the bounds of a HorizontalPodAutoscaler and the original
+// bounds recorded on it, nil if none
+func hpaBounds(hpa *autoscalingv2.HorizontalPodAutoscaler) (Bounds, *Bounds, error) {
+	original, err := originalBounds(hpa.Annotations)
+	if err != nil {
+		return Bounds{}, nil, fmt.Errorf("error reading HorizontalPodAutoscaler %s: %v", hpa.Name, err)
+	}
+	return Bounds{Min: replicasOrDefault(hpa.Spec.MinReplicas), Max: int(hpa.Spec.MaxReplicas)}, original, nil
+}
+
+// applyHPAMode changes the HorizontalPodAutoscalers targeting a resource about to
+// be scaled to replicas according to Options.HPAMode. The bounds they had before
+// are recorded on them, so HPAUnpin can restore them. HPAs are inactive while
+// their target has zero replicas, so they are left alone when scaling to zero.
+// The returned warnings describe what was or, in a dry run, would be done.
+func (s *Scaler) applyHPAMode(ctx context.Context, namespace string, hpas []autoscalingv2.HorizontalPodAutoscaler, replicas int) ([]string, error) {
+	var warnings []string
+	for _, hpa := range hpas {
+		current, original, err := hpaBounds(&hpa)
+		if err != nil {
+			return warnings, err
+		}
+
+		var next Bounds
+		var plan, done string
+		switch s.opts.HPAMode {
+		case HPAAdjust, HPAPin:
+			if replicas == 0 {
+				continue
+			}
+			next = Bounds{Min: replicas, Max: max(current.Max, replicas)}
+			plan = fmt.Sprintf("set HorizontalPodAutoscaler %s minReplicas to %d", hpa.Name, replicas)
+			done = plan
+			if s.opts.HPAMode == HPAPin {
+				next.Max = replicas
+				plan = fmt.Sprintf("pin HorizontalPodAutoscaler %s to %d replicas", hpa.Name, replicas)
+				done = fmt.Sprintf("pinned HorizontalPodAutoscaler %s to %d replicas", hpa.Name, replicas)
+			}
+			if original == nil {
+				original = &current
+			}
+
+		case HPAUnpin:
+			if original == nil {
+				continue
+			}
+			next, original = *original, nil
+			plan = fmt.Sprintf("restore HorizontalPodAutoscaler %s to %s replicas", hpa.Name, next)
+			done = fmt.Sprintf("restored HorizontalPodAutoscaler %s to %s replicas", hpa.Name, next)
+
+		default:
+			if replicas > 0 {
+				warnings = append(warnings, fmt.Sprintf("targeted by HorizontalPodAutoscaler %s, which may change the replicas", hpa.Name))
+			}
+			continue
+		}
+
+		if s.opts.DryRun {
+			warnings = append(warnings, "would "+plan)
+			continue
+		}
+		err = s.apply(func(clients Clients) error {
+			return hpaKind{}.SetBounds(ctx, clients, namespace, hpa.Name, next, original)
+		})
+		if err != nil {
+			return warnings, fmt.Errorf("error updating HorizontalPodAutoscaler %s: %v", hpa.Name, err)
+		}
+		warnings = append(warnings, done)
+	}
+	return warnings, nil
+}
@@ -0,0 +1,253 @@
+package scale
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// newHPAClientset returns a fake clientset with a deployment of 2 replicas
+// targeted by an HPA scaling it between 2 and 10 replicas. The clientset tracks
+// managed fields, so recorded annotations are removed as with server-side apply,
+// and changing the bounds of the HPA, created by another field manager, needs
+// ForceConflicts.
+func newHPAClientset() *fake.Clientset {
+	return newManagedClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-hpa", Namespace: "default"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "api"},
+				MinReplicas:    int32Ptr(2),
+				MaxReplicas:    10,
+			},
+		},
+	)
+}
+
+// newManagedClientset returns a fake clientset tracking managed fields, with the
+// objects created as by kubectl create
+func newManagedClientset(objects ...runtime.Object) *fake.Clientset {
+	clientset := fake.NewClientset()
+	for _, obj := range objects {
+		gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+		if err != nil {
+			panic(err)
+		}
+		gvr, _ := meta.UnsafeGuessKindToResource(gvks[0])
+		namespace := obj.(metav1.Object).GetNamespace()
+		if err := clientset.Tracker().Create(gvr, obj, namespace, metav1.CreateOptions{FieldManager: "kubectl-create"}); err != nil {
+			panic(err)
+		}
+	}
+	return clientset
+}
+
+func getHPA(t *testing.T, clientset *fake.Clientset) *autoscalingv2.HorizontalPodAutoscaler {
+	t.Helper()
+	hpa, err := clientset.AutoscalingV2().HorizontalPodAutoscalers("default").Get(context.TODO(), "api-hpa", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get HPA: %v", err)
+	}
+	return hpa
+}
+
+func TestScaleHPATargetWarnsOrSkips(t *testing.T) {
+	clientset := newHPAClientset()
+
+	result := NewScaler(clientset, nil, Options{Replicas: 4, CurrentReplicas: -1, ForceConflicts: true}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to scale deployment: %v", result.Err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Expected a warning about the HPA, got %v", result.Warnings)
+	}
+
+	result = NewScaler(clientset, nil, Options{Replicas: 0, CurrentReplicas: -1, HPAMode: HPASkip}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Skipped != "targeted by HorizontalPodAutoscaler api-hpa" {
+		t.Errorf("Expected deployment to be skipped, got %q", result.Skipped)
+	}
+
+	// Other resources are unaffected by the HPA
+	clientset.AppsV1().Deployments("default").Create(context.TODO(), &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(1)},
+	}, metav1.CreateOptions{})
+	result = NewScaler(clientset, nil, Options{Replicas: 3, CurrentReplicas: -1, ForceConflicts: true, HPAMode: HPASkip}).Scale(context.TODO(), "deployment", "web", "default")
+	if !result.Succeeded() || len(result.Warnings) != 0 {
+		t.Errorf("Expected web to be scaled without warnings, got %+v", result)
+	}
+}
+
+func TestScaleHPATargetAdjust(t *testing.T) {
+	clientset := newHPAClientset()
+
+	result := NewScaler(clientset, nil, Options{Replicas: 12, CurrentReplicas: -1, ForceConflicts: true, HPAMode: HPAAdjust}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to scale deployment: %v", result.Err)
+	}
+
+	hpa := getHPA(t, clientset)
+	if *hpa.Spec.MinReplicas != 12 || hpa.Spec.MaxReplicas != 12 {
+		t.Errorf("Expected HPA bounds 12-12, got %d-%d", *hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+	}
+	if hpa.Annotations[OriginalMinReplicasAnnotation] != "2" || hpa.Annotations[OriginalMaxReplicasAnnotation] != "10" {
+		t.Errorf("Expected original bounds to be recorded, got %v", hpa.Annotations)
+	}
+}
+
+func TestScaleHPATargetPinAndUnpin(t *testing.T) {
+	clientset := newHPAClientset()
+
+	result := NewScaler(clientset, nil, Options{Replicas: 5, CurrentReplicas: -1, ForceConflicts: true, HPAMode: HPAPin}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to scale deployment: %v", result.Err)
+	}
+
+	hpa := getHPA(t, clientset)
+	if *hpa.Spec.MinReplicas != 5 || hpa.Spec.MaxReplicas != 5 {
+		t.Errorf("Expected HPA pinned to 5-5, got %d-%d", *hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+	}
+	if hpa.Annotations[OriginalMinReplicasAnnotation] != "2" || hpa.Annotations[OriginalMaxReplicasAnnotation] != "10" {
+		t.Errorf("Expected original bounds to be recorded, got %v", hpa.Annotations)
+	}
+
+	// Pinning again keeps the original bounds
+	NewScaler(clientset, nil, Options{Replicas: 6, CurrentReplicas: -1, ForceConflicts: true, HPAMode: HPAPin}).Scale(context.TODO(), "deployment", "api", "default")
+	if hpa = getHPA(t, clientset); hpa.Annotations[OriginalMaxReplicasAnnotation] != "10" {
+		t.Errorf("Expected original max replicas 10, got %q", hpa.Annotations[OriginalMaxReplicasAnnotation])
+	}
+
+	result = NewScaler(clientset, nil, Options{Replicas: 2, CurrentReplicas: -1, ForceConflicts: true, HPAMode: HPAUnpin}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to scale deployment: %v", result.Err)
+	}
+
+	hpa = getHPA(t, clientset)
+	if *hpa.Spec.MinReplicas != 2 || hpa.Spec.MaxReplicas != 10 {
+		t.Errorf("Expected HPA bounds restored to 2-10, got %d-%d", *hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+	}
+	if _, ok := hpa.Annotations[OriginalMaxReplicasAnnotation]; ok {
+		t.Error("Expected original bounds annotations to be removed")
+	}
+}
+
+func TestScaleHPABounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		expected Bounds
+	}{
+		{"replicas keep the ratio", Options{Replicas: 4}, Bounds{Min: 4, Max: 20}},
+		{"min keeps the ratio", Options{Replicas: -1, MinReplicas: "+1"}, Bounds{Min: 3, Max: 15}},
+		{"max keeps the ratio", Options{Replicas: -1, MaxReplicas: "5"}, Bounds{Min: 1, Max: 5}},
+		{"independent max", Options{Replicas: -1, MaxReplicas: "-5", IndependentBounds: true}, Bounds{Min: 2, Max: 5}},
+		{"independent replicas", Options{Replicas: 3, IndependentBounds: true}, Bounds{Min: 3, Max: 10}},
+		{"replicas and max", Options{Replicas: 1, MaxReplicas: "+2"}, Bounds{Min: 1, Max: 12}},
+		{"zero replicas keep the max", Options{Replicas: 0}, Bounds{Min: 0, Max: 10}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clientset := newHPAClientset()
+			test.opts.CurrentReplicas, test.opts.ForceConflicts = -1, true
+
+			result := NewScaler(clientset, nil, test.opts).Scale(context.TODO(), "hpa", "api-hpa", "default")
+			if result.Err != nil {
+				t.Fatalf("Failed to scale HPA: %v", result.Err)
+			}
+			if result.Replicas != test.expected.Min {
+				t.Errorf("Expected result replicas %d, got %d", test.expected.Min, result.Replicas)
+			}
+
+			hpa := getHPA(t, clientset)
+			if *hpa.Spec.MinReplicas != int32(test.expected.Min) || hpa.Spec.MaxReplicas != int32(test.expected.Max) {
+				t.Errorf("Expected HPA bounds %s, got %d-%d", test.expected, *hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+			}
+			if hpa.Spec.ScaleTargetRef.Name != "api" {
+				t.Errorf("Expected scaleTargetRef to be kept, got %+v", hpa.Spec.ScaleTargetRef)
+			}
+		})
+	}
+}
+
+func TestScaleHPAInvalidBounds(t *testing.T) {
+	for _, opts := range []Options{
+		{Replicas: -1, MinReplicas: "11", IndependentBounds: true},
+		{Replicas: -1, MaxReplicas: "-10"},
+	} {
+		opts.CurrentReplicas, opts.ForceConflicts = -1, true
+		result := NewScaler(newHPAClientset(), nil, opts).Scale(context.TODO(), "hpa", "api-hpa", "default")
+		if result.Err == nil {
+			t.Errorf("Expected error for %+v, got nil", opts)
+		}
+	}
+
+	// Bounds only apply to kinds with bounds
+	result := NewScaler(newHPAClientset(), nil, Options{Replicas: -1, CurrentReplicas: -1, MaxReplicas: "4"}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err == nil {
+		t.Error("Expected error for max replicas on a deployment, got nil")
+	}
+}
+
+func TestScaleHPARestoreBounds(t *testing.T) {
+	clientset := newHPAClientset()
+
+	result := NewScaler(clientset, nil, Options{Replicas: -1, CurrentReplicas: -1, RestoreBounds: true}).Scale(context.TODO(), "hpa", "api-hpa", "default")
+	if result.Skipped == "" {
+		t.Errorf("Expected HPA without recorded bounds to be skipped, got %+v", result)
+	}
+
+	for _, opts := range []Options{{Replicas: 6}, {Replicas: -1, MaxReplicas: "+10"}} {
+		opts.CurrentReplicas, opts.ForceConflicts = -1, true
+		if result := NewScaler(clientset, nil, opts).Scale(context.TODO(), "hpa", "api-hpa", "default"); result.Err != nil {
+			t.Fatalf("Failed to scale HPA: %v", result.Err)
+		}
+	}
+
+	result = NewScaler(clientset, nil, Options{Replicas: -1, CurrentReplicas: -1, ForceConflicts: true, RestoreBounds: true}).Scale(context.TODO(), "hpa", "api-hpa", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to restore HPA: %v", result.Err)
+	}
+
+	hpa := getHPA(t, clientset)
+	if *hpa.Spec.MinReplicas != 2 || hpa.Spec.MaxReplicas != 10 {
+		t.Errorf("Expected HPA bounds restored to 2-10, got %d-%d", *hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+	}
+	if _, ok := hpa.Annotations[OriginalMinReplicasAnnotation]; ok {
+		t.Error("Expected original bounds annotations to be removed")
+	}
+}
+
+func TestScaleHPAWithoutMinReplicas(t *testing.T) {
+	clientset := newManagedClientset(&autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-hpa", Namespace: "default"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "api"},
+			MaxReplicas:    4,
+		},
+	})
+
+	result := NewScaler(clientset, nil, Options{Replicas: 2, CurrentReplicas: -1, ForceConflicts: true}).Scale(context.TODO(), "hpa", "api-hpa", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to scale HPA: %v", result.Err)
+	}
+	if result.PreviousReplicas != 1 {
+		t.Errorf("Expected previous replicas to default to 1, got %d", result.PreviousReplicas)
+	}
+
+	hpa := getHPA(t, clientset)
+	if *hpa.Spec.MinReplicas != 2 || hpa.Spec.MaxReplicas != 8 {
+		t.Errorf("Expected HPA bounds 2-8, got %d-%d", *hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+	}
+}
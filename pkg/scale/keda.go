@@ -0,0 +1,217 @@
+package scale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// KEDAPausedReplicasAnnotation pauses a KEDA ScaledObject, holding its target at the given replicas
+const KEDAPausedReplicasAnnotation = "autoscaling.keda.sh/paused-replicas"
+
+// ScaledObjectGVR is the resource of KEDA ScaledObjects
+var ScaledObjectGVR = schema.GroupVersionResource{Group: "keda.sh", Version: "v1alpha1", Resource: "scaledobjects"}
+
+// scaledObjectKind scales KEDA ScaledObjects by pausing them at the replicas with
+// KEDAPausedReplicasAnnotation, through the dynamic client. Their replicas are
+// those of the scale target while they are not paused.
+type scaledObjectKind struct{}
+
+func (scaledObjectKind) Name() string { return "scaledobject" }
+
+func (scaledObjectKind) Aliases() []string { return []string{"so", "scaledobjects"} }
+
+func (scaledObjectKind) List(ctx context.Context, clients Clients, namespace string) ([]string, error) {
+	scaledObjects, err := listScaledObjects(ctx, clients, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, scaledObject := range scaledObjects {
+		names = append(names, scaledObject.GetName())
+	}
+	return names, nil
+}
+
+func (scaledObjectKind) Status(ctx context.Context, clients Clients, namespace string) ([]ReplicaStatus, error) {
+	scaledObjects, err := listScaledObjects(ctx, clients, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// Targets are read from the status of their kind, listed once per kind.
+	// ScaledObjects targeting an unsupported kind are skipped with a warning.
+	targetStatuses := map[string]map[string]ReplicaStatus{}
+	var statuses []ReplicaStatus
+	for _, scaledObject := range scaledObjects {
+		target, targetName, err := scaleTarget(&scaledObject)
+		if err != nil {
+			statuses = append(statuses, ReplicaStatus{Name: scaledObject.GetName(), Warning: fmt.Sprintf("skipped: %v", err)})
+			continue
+		}
+
+		if _, ok := targetStatuses[target.Name()]; !ok {
+			targetStatuses[target.Name()] = map[string]ReplicaStatus{}
+			kindStatuses, err := target.Status(ctx, clients, namespace)
+			if err != nil {
+				return nil, err
+			}
+			for _, status := range kindStatuses {
+				targetStatuses[target.Name()][status.Name] = status
+			}
+		}
+
+		status := targetStatuses[target.Name()][targetName]
+		status.Name = scaledObject.GetName()
+		if paused, ok, err := pausedReplicas(&scaledObject); err != nil {
+			return nil, err
+		} else if ok {
+			status.Desired = paused
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (scaledObjectKind) GetReplicas(ctx context.Context, clients Clients, namespace, name string) (int, error) {
+	scaledObject, err := getScaledObject(ctx, clients, namespace, name)
+	if err != nil {
+		return -1, err
+	}
+
+	if paused, ok, err := pausedReplicas(scaledObject); err != nil || ok {
+		return paused, err
+	}
+
+	target, targetName, err := scaleTarget(scaledObject)
+	if err != nil {
+		return -1, err
+	}
+	return target.GetReplicas(ctx, clients, namespace, targetName)
+}
+
+func (scaledObjectKind) Metadata(ctx context.Context, clients Clients, namespace, name string) (metav1.ObjectMeta, error) {
+	scaledObject, err := getScaledObject(ctx, clients, namespace, name)
+	if err != nil {
+		return metav1.ObjectMeta{}, err
+	}
+
+	return metav1.ObjectMeta{
+		Name:            scaledObject.GetName(),
+		Namespace:       scaledObject.GetNamespace(),
+		Labels:          scaledObject.GetLabels(),
+		Annotations:     scaledObject.GetAnnotations(),
+		OwnerReferences: scaledObject.GetOwnerReferences(),
+	}, nil
+}
+
+func (scaledObjectKind) SetReplicas(ctx context.Context, clients Clients, namespace, name string, replicas int) error {
+	if clients.Dynamic == nil {
+		return fmt.Errorf("a dynamic client is required to scale scaledobjects")
+	}
+
+	scaledObject := &unstructured.Unstructured{}
+	scaledObject.SetAPIVersion(ScaledObjectGVR.GroupVersion().String())
+	scaledObject.SetKind("ScaledObject")
+	scaledObject.SetName(name)
+	scaledObject.SetNamespace(namespace)
+	scaledObject.SetAnnotations(map[string]string{KEDAPausedReplicasAnnotation: strconv.Itoa(replicas)})
+
+	_, err := clients.Dynamic.Resource(ScaledObjectGVR).Namespace(namespace).Apply(ctx, name, scaledObject, clients.ApplyOptions())
+	return err
+}
+
+// Resume removes KEDAPausedReplicasAnnotation, however it was set, handing the
+// target back to KEDA
+func (scaledObjectKind) Resume(ctx context.Context, clients Clients, namespace, name string) error {
+	if clients.Dynamic == nil {
+		return fmt.Errorf("a dynamic client is required to resume scaledobjects")
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{"annotations": map[string]any{KEDAPausedReplicasAnnotation: nil}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = clients.Dynamic.Resource(ScaledObjectGVR).Namespace(namespace).Patch(ctx, name, types.MergePatchType, patch,
+		metav1.PatchOptions{FieldManager: FieldManager})
+	return err
+}
+
+func (scaledObjectKind) Ready(ctx context.Context, clients Clients, namespace, name string) (bool, error) {
+	scaledObject, err := getScaledObject(ctx, clients, namespace, name)
+	if err != nil {
+		return false, err
+	}
+
+	target, targetName, err := scaleTarget(scaledObject)
+	if err != nil {
+		return false, err
+	}
+	return target.Ready(ctx, clients, namespace, targetName)
+}
+
+// listScaledObjects lists the ScaledObjects in a namespace with the dynamic client,
+// none if KEDA is not installed
+func listScaledObjects(ctx context.Context, clients Clients, namespace string) ([]unstructured.Unstructured, error) {
+	if clients.Dynamic == nil {
+		return nil, fmt.Errorf("a dynamic client is required to list scaledobjects")
+	}
+
+	list, err := clients.Dynamic.Resource(ScaledObjectGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if apiMissing(err) {
+		// Without KEDA installed there are no ScaledObjects
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// getScaledObject reads a ScaledObject with the dynamic client
+func getScaledObject(ctx context.Context, clients Clients, namespace, name string) (*unstructured.Unstructured, error) {
+	if clients.Dynamic == nil {
+		return nil, fmt.Errorf("a dynamic client is required to get scaledobjects")
+	}
+	return clients.Dynamic.Resource(ScaledObjectGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// pausedReplicas returns the replicas a ScaledObject is paused at, if it is paused
+func pausedReplicas(scaledObject *unstructured.Unstructured) (int, bool, error) {
+	value, ok := scaledObject.GetAnnotations()[KEDAPausedReplicasAnnotation]
+	if !ok {
+		return -1, false, nil
+	}
+
+	replicas, err := strconv.Atoi(value)
+	if err != nil {
+		return -1, false, fmt.Errorf("invalid %s annotation on scaledobject %s: %v", KEDAPausedReplicasAnnotation, scaledObject.GetName(), err)
+	}
+	return replicas, true, nil
+}
+
+// scaleTarget returns the registered kind and name of the resource a ScaledObject scales
+func scaleTarget(scaledObject *unstructured.Unstructured) (KindScaler, string, error) {
+	name, _, _ := unstructured.NestedString(scaledObject.Object, "spec", "scaleTargetRef", "name")
+	kindName, _, _ := unstructured.NestedString(scaledObject.Object, "spec", "scaleTargetRef", "kind")
+	if kindName == "" {
+		kindName = "Deployment"
+	}
+
+	kind, err := Lookup(strings.ToLower(kindName))
+	if err != nil {
+		return nil, "", fmt.Errorf("scale target of scaledobject %s: %v", scaledObject.GetName(), err)
+	}
+	return kind, name, nil
+}
@@ -0,0 +1,201 @@
+package scale
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newScaledObject returns a ScaledObject scaling the worker deployment in a namespace
+func newScaledObject(namespace string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "keda.sh/v1alpha1",
+		"kind":       "ScaledObject",
+		"metadata":   map[string]any{"name": "worker", "namespace": namespace},
+		"spec": map[string]any{
+			"scaleTargetRef":  map[string]any{"name": "worker"},
+			"maxReplicaCount": int64(20),
+		},
+	}}
+}
+
+// newKEDAClients returns fake clients with a worker deployment of 3 replicas
+// scaled by a ScaledObject in the default and staging namespaces
+func newKEDAClients() (*fake.Clientset, *dynamicfake.FakeDynamicClient) {
+	var objects []runtime.Object
+	for _, ns := range []string{"default", "staging"} {
+		objects = append(objects, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: ns},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+		})
+	}
+	clientset := fake.NewSimpleClientset(objects...)
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{ScaledObjectGVR: "ScaledObjectList"},
+		newScaledObject("default"), newScaledObject("staging"))
+	dynamicClient.PrependReactor("patch", "scaledobjects", applyAsMergePatch(dynamicClient.Tracker()))
+
+	return clientset, dynamicClient
+}
+
+// applyAsMergePatch handles server-side apply, which the fake dynamic client
+// does not support for unstructured objects, as a JSON merge patch
+func applyAsMergePatch(tracker k8stesting.ObjectTracker) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(k8stesting.PatchAction)
+		if patchAction.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+
+		existing, err := tracker.Get(patchAction.GetResource(), patchAction.GetNamespace(), patchAction.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		original, err := json.Marshal(existing)
+		if err != nil {
+			return true, nil, err
+		}
+		patched, err := jsonpatch.MergePatch(original, patchAction.GetPatch())
+		if err != nil {
+			return true, nil, err
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patched); err != nil {
+			return true, nil, err
+		}
+		return true, obj, tracker.Update(patchAction.GetResource(), obj, patchAction.GetNamespace())
+	}
+}
+
+func TestScaleScaledObjectPauseAndResume(t *testing.T) {
+	clientset, dynamicClient := newKEDAClients()
+	ctx := context.TODO()
+
+	results, err := NewScaler(clientset, dynamicClient, Options{Replicas: 0, CurrentReplicas: -1}).ScaleAll(ctx, "so", []string{"default", "staging"})
+	if err != nil {
+		t.Fatalf("Failed to scale scaledobjects: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			t.Fatalf("Failed to scale scaledobject in %s: %v", result.Namespace, result.Err)
+		}
+		if result.PreviousReplicas != 3 {
+			t.Errorf("Expected previous replicas from the deployment, got %d", result.PreviousReplicas)
+		}
+
+		scaledObject, err := dynamicClient.Resource(ScaledObjectGVR).Namespace(result.Namespace).Get(ctx, "worker", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get scaledobject: %v", err)
+		}
+		if paused := scaledObject.GetAnnotations()[KEDAPausedReplicasAnnotation]; paused != "0" {
+			t.Errorf("Expected scaledobject to be paused at 0 replicas, got %q", paused)
+		}
+		if maxReplicas, _, _ := unstructured.NestedInt64(scaledObject.Object, "spec", "maxReplicaCount"); maxReplicas != 20 {
+			t.Errorf("Expected spec to be kept, got maxReplicaCount %d", maxReplicas)
+		}
+	}
+
+	result := NewScaler(clientset, dynamicClient, Options{Replicas: -1, CurrentReplicas: -1, Resume: true}).Scale(ctx, "scaledobject", "worker", "default")
+	if result.Err != nil || !result.Resumed {
+		t.Fatalf("Failed to resume scaledobject: %+v", result)
+	}
+	if result.PreviousReplicas != 0 {
+		t.Errorf("Expected paused replicas 0, got %d", result.PreviousReplicas)
+	}
+
+	scaledObject, err := dynamicClient.Resource(ScaledObjectGVR).Namespace("default").Get(ctx, "worker", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get scaledobject: %v", err)
+	}
+	if _, ok := scaledObject.GetAnnotations()[KEDAPausedReplicasAnnotation]; ok {
+		t.Error("Expected paused replicas annotation to be removed")
+	}
+}
+
+func TestResumeUnsupportedKind(t *testing.T) {
+	clientset, dynamicClient := newKEDAClients()
+
+	result := NewScaler(clientset, dynamicClient, Options{Replicas: -1, CurrentReplicas: -1, Resume: true}).Scale(context.TODO(), "deployment", "worker", "default")
+	if result.Err == nil {
+		t.Error("Expected error resuming a deployment, got nil")
+	}
+}
+
+func TestScaledObjectStatusSkipsUnsupportedKind(t *testing.T) {
+	clientset, dynamicClient := newKEDAClients()
+	unsupported := newScaledObject("default")
+	unsupported.SetName("queue")
+	unstructured.SetNestedField(unsupported.Object, "Rollout", "spec", "scaleTargetRef", "kind")
+	if _, err := dynamicClient.Resource(ScaledObjectGVR).Namespace("default").Create(context.TODO(), unsupported, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create ScaledObject: %v", err)
+	}
+
+	kind, _ := Lookup("scaledobject")
+	statuses, err := kind.Status(context.TODO(), Clients{Kubernetes: clientset, Dynamic: dynamicClient}, "default")
+	if err != nil {
+		t.Fatalf("Failed to list ScaledObject statuses: %v", err)
+	}
+
+	warnings := map[string]string{}
+	for _, status := range statuses {
+		warnings[status.Name] = status.Warning
+	}
+	if len(statuses) != 2 || warnings["worker"] != "" || warnings["queue"] == "" {
+		t.Errorf("Expected worker to be listed and queue to be skipped with a warning, got %+v", statuses)
+	}
+}
+
+// newMissingAPIClient returns a fake dynamic client that fails to list a
+// resource with err, as when its CRD is not installed
+func newMissingAPIClient(gvr schema.GroupVersionResource, err error) *dynamicfake.FakeDynamicClient {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "List"})
+	dynamicClient.PrependReactor("list", gvr.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, err
+	})
+	return dynamicClient
+}
+
+// missingAPIErrors are the errors listing a resource whose CRD is not installed
+func missingAPIErrors(gvr schema.GroupVersionResource) map[string]error {
+	return map[string]error{
+		"not found": apierrors.NewNotFound(gvr.GroupResource(), ""),
+		"no match":  &meta.NoResourceMatchError{PartialResource: gvr},
+	}
+}
+
+func TestScaledObjectsWithoutKEDA(t *testing.T) {
+	for name, err := range missingAPIErrors(ScaledObjectGVR) {
+		t.Run(name, func(t *testing.T) {
+			clients := Clients{Kubernetes: fake.NewSimpleClientset(), Dynamic: newMissingAPIClient(ScaledObjectGVR, err)}
+			kind, _ := Lookup("scaledobject")
+
+			if names, err := kind.List(context.TODO(), clients, "default"); err != nil || len(names) != 0 {
+				t.Errorf("Expected no ScaledObjects without KEDA, got %v: %v", names, err)
+			}
+			if statuses, err := kind.Status(context.TODO(), clients, "default"); err != nil || len(statuses) != 0 {
+				t.Errorf("Expected no statuses without KEDA, got %v: %v", statuses, err)
+			}
+		})
+	}
+}
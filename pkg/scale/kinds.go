@@ -0,0 +1,709 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
+	autoscalingv2ac "k8s.io/client-go/applyconfigurations/autoscaling/v2"
+	batchv1ac "k8s.io/client-go/applyconfigurations/batch/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+func init() {
+	Register(deploymentKind{})
+	Register(statefulSetKind{})
+	Register(replicaSetKind{})
+	Register(replicationControllerKind{})
+	Register(jobKind{})
+	Register(cronJobKind{})
+	Register(hpaKind{})
+	Register(scaledObjectKind{})
+	Register(knativeServiceKind{})
+}
+
+// replicasOrDefault returns the value of an optional replica field, or the API default when unset
+func replicasOrDefault(replicas *int32) int {
+	if replicas == nil {
+		return 1
+	}
+	return int(*replicas)
+}
+
+// deploymentKind scales apps/v1 Deployments
+type deploymentKind struct{}
+
+func (deploymentKind) Name() string { return "deployment" }
+
+func (deploymentKind) Aliases() []string { return []string{"deploy", "deployments"} }
+
+func (deploymentKind) List(ctx context.Context, clients Clients, namespace string) ([]string, error) {
+	deployments, err := clients.Kubernetes.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, deployment := range deployments.Items {
+		names = append(names, deployment.Name)
+	}
+	return names, nil
+}
+
+func (deploymentKind) Status(ctx context.Context, clients Clients, namespace string) ([]ReplicaStatus, error) {
+	deployments, err := clients.Kubernetes.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []ReplicaStatus
+	for _, deployment := range deployments.Items {
+		statuses = append(statuses, ReplicaStatus{
+			Name:    deployment.Name,
+			Desired: replicasOrDefault(deployment.Spec.Replicas),
+			Ready:   int(deployment.Status.ReadyReplicas),
+		})
+	}
+	return statuses, nil
+}
+
+func (deploymentKind) GetReplicas(ctx context.Context, clients Clients, namespace, name string) (int, error) {
+	deployment, err := clients.Kubernetes.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return -1, err
+	}
+	return replicasOrDefault(deployment.Spec.Replicas), nil
+}
+
+func (deploymentKind) Metadata(ctx context.Context, clients Clients, namespace, name string) (metav1.ObjectMeta, error) {
+	deployment, err := clients.Kubernetes.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return metav1.ObjectMeta{}, err
+	}
+	return deployment.ObjectMeta, nil
+}
+
+func (deploymentKind) SetReplicas(ctx context.Context, clients Clients, namespace, name string, replicas int) error {
+	deployment := appsv1ac.Deployment(name, namespace).
+		WithSpec(appsv1ac.DeploymentSpec().WithReplicas(int32(replicas)))
+	_, err := clients.Kubernetes.AppsV1().Deployments(namespace).Apply(ctx, deployment, clients.ApplyOptions())
+	return err
+}
+
+func (deploymentKind) Ready(ctx context.Context, clients Clients, namespace, name string) (bool, error) {
+	deployment, err := clients.Kubernetes.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	replicas := int32(replicasOrDefault(deployment.Spec.Replicas))
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas == replicas &&
+		deployment.Status.AvailableReplicas == replicas &&
+		deployment.Status.Replicas == replicas, nil
+}
+
+func (deploymentKind) PodTemplate(ctx context.Context, clients Clients, namespace, name string) (*corev1.PodTemplateSpec, error) {
+	deployment, err := clients.Kubernetes.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &deployment.Spec.Template, nil
+}
+
+// statefulSetKind scales apps/v1 StatefulSets
+type statefulSetKind struct{}
+
+func (statefulSetKind) Name() string { return "statefulset" }
+
+func (statefulSetKind) Aliases() []string { return []string{"sts", "statefulsets"} }
+
+func (statefulSetKind) List(ctx context.Context, clients Clients, namespace string) ([]string, error) {
+	statefulsets, err := clients.Kubernetes.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, statefulset := range statefulsets.Items {
+		names = append(names, statefulset.Name)
+	}
+	return names, nil
+}
+
+func (statefulSetKind) Status(ctx context.Context, clients Clients, namespace string) ([]ReplicaStatus, error) {
+	statefulsets, err := clients.Kubernetes.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []ReplicaStatus
+	for _, statefulset := range statefulsets.Items {
+		statuses = append(statuses, ReplicaStatus{
+			Name:    statefulset.Name,
+			Desired: replicasOrDefault(statefulset.Spec.Replicas),
+			Ready:   int(statefulset.Status.ReadyReplicas),
+		})
+	}
+	return statuses, nil
+}
+
+func (statefulSetKind) GetReplicas(ctx context.Context, clients Clients, namespace, name string) (int, error) {
+	statefulset, err := clients.Kubernetes.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return -1, err
+	}
+	return replicasOrDefault(statefulset.Spec.Replicas), nil
+}
+
+func (statefulSetKind) Metadata(ctx context.Context, clients Clients, namespace, name string) (metav1.ObjectMeta, error) {
+	statefulset, err := clients.Kubernetes.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return metav1.ObjectMeta{}, err
+	}
+	return statefulset.ObjectMeta, nil
+}
+
+func (statefulSetKind) SetReplicas(ctx context.Context, clients Clients, namespace, name string, replicas int) error {
+	statefulset := appsv1ac.StatefulSet(name, namespace).
+		WithSpec(appsv1ac.StatefulSetSpec().WithReplicas(int32(replicas)))
+	_, err := clients.Kubernetes.AppsV1().StatefulSets(namespace).Apply(ctx, statefulset, clients.ApplyOptions())
+	return err
+}
+
+func (statefulSetKind) Ready(ctx context.Context, clients Clients, namespace, name string) (bool, error) {
+	statefulset, err := clients.Kubernetes.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	replicas := int32(replicasOrDefault(statefulset.Spec.Replicas))
+	return statefulset.Status.ObservedGeneration >= statefulset.Generation &&
+		statefulset.Status.ReadyReplicas == replicas &&
+		statefulset.Status.Replicas == replicas, nil
+}
+
+func (statefulSetKind) PodTemplate(ctx context.Context, clients Clients, namespace, name string) (*corev1.PodTemplateSpec, error) {
+	statefulset, err := clients.Kubernetes.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &statefulset.Spec.Template, nil
+}
+
+// replicaSetKind scales apps/v1 ReplicaSets
+type replicaSetKind struct{}
+
+func (replicaSetKind) Name() string { return "replicaset" }
+
+func (replicaSetKind) Aliases() []string { return []string{"rs", "replicasets"} }
+
+func (replicaSetKind) List(ctx context.Context, clients Clients, namespace string) ([]string, error) {
+	replicasets, err := clients.Kubernetes.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, replicaset := range replicasets.Items {
+		names = append(names, replicaset.Name)
+	}
+	return names, nil
+}
+
+func (replicaSetKind) Status(ctx context.Context, clients Clients, namespace string) ([]ReplicaStatus, error) {
+	replicasets, err := clients.Kubernetes.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []ReplicaStatus
+	for _, replicaset := range replicasets.Items {
+		statuses = append(statuses, ReplicaStatus{
+			Name:    replicaset.Name,
+			Desired: replicasOrDefault(replicaset.Spec.Replicas),
+			Ready:   int(replicaset.Status.ReadyReplicas),
+		})
+	}
+	return statuses, nil
+}
+
+func (replicaSetKind) GetReplicas(ctx context.Context, clients Clients, namespace, name string) (int, error) {
+	replicaset, err := clients.Kubernetes.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return -1, err
+	}
+	return replicasOrDefault(replicaset.Spec.Replicas), nil
+}
+
+func (replicaSetKind) Metadata(ctx context.Context, clients Clients, namespace, name string) (metav1.ObjectMeta, error) {
+	replicaset, err := clients.Kubernetes.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return metav1.ObjectMeta{}, err
+	}
+	return replicaset.ObjectMeta, nil
+}
+
+func (replicaSetKind) SetReplicas(ctx context.Context, clients Clients, namespace, name string, replicas int) error {
+	replicaset := appsv1ac.ReplicaSet(name, namespace).
+		WithSpec(appsv1ac.ReplicaSetSpec().WithReplicas(int32(replicas)))
+	_, err := clients.Kubernetes.AppsV1().ReplicaSets(namespace).Apply(ctx, replicaset, clients.ApplyOptions())
+	return err
+}
+
+func (replicaSetKind) Ready(ctx context.Context, clients Clients, namespace, name string) (bool, error) {
+	replicaset, err := clients.Kubernetes.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	replicas := int32(replicasOrDefault(replicaset.Spec.Replicas))
+	return replicaset.Status.ObservedGeneration >= replicaset.Generation &&
+		replicaset.Status.ReadyReplicas == replicas &&
+		replicaset.Status.Replicas == replicas, nil
+}
+
+func (replicaSetKind) PodTemplate(ctx context.Context, clients Clients, namespace, name string) (*corev1.PodTemplateSpec, error) {
+	replicaset, err := clients.Kubernetes.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &replicaset.Spec.Template, nil
+}
+
+// replicationControllerKind scales core/v1 ReplicationControllers
+type replicationControllerKind struct{}
+
+func (replicationControllerKind) Name() string { return "replicationcontroller" }
+
+func (replicationControllerKind) Aliases() []string { return []string{"rc", "replicationcontrollers"} }
+
+func (replicationControllerKind) List(ctx context.Context, clients Clients, namespace string) ([]string, error) {
+	rcs, err := clients.Kubernetes.CoreV1().ReplicationControllers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, rc := range rcs.Items {
+		names = append(names, rc.Name)
+	}
+	return names, nil
+}
+
+func (replicationControllerKind) Status(ctx context.Context, clients Clients, namespace string) ([]ReplicaStatus, error) {
+	rcs, err := clients.Kubernetes.CoreV1().ReplicationControllers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []ReplicaStatus
+	for _, rc := range rcs.Items {
+		statuses = append(statuses, ReplicaStatus{
+			Name:    rc.Name,
+			Desired: replicasOrDefault(rc.Spec.Replicas),
+			Ready:   int(rc.Status.ReadyReplicas),
+		})
+	}
+	return statuses, nil
+}
+
+func (replicationControllerKind) GetReplicas(ctx context.Context, clients Clients, namespace, name string) (int, error) {
+	rc, err := clients.Kubernetes.CoreV1().ReplicationControllers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return -1, err
+	}
+	return replicasOrDefault(rc.Spec.Replicas), nil
+}
+
+func (replicationControllerKind) Metadata(ctx context.Context, clients Clients, namespace, name string) (metav1.ObjectMeta, error) {
+	rc, err := clients.Kubernetes.CoreV1().ReplicationControllers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return metav1.ObjectMeta{}, err
+	}
+	return rc.ObjectMeta, nil
+}
+
+func (replicationControllerKind) SetReplicas(ctx context.Context, clients Clients, namespace, name string, replicas int) error {
+	rc := corev1ac.ReplicationController(name, namespace).
+		WithSpec(corev1ac.ReplicationControllerSpec().WithReplicas(int32(replicas)))
+	_, err := clients.Kubernetes.CoreV1().ReplicationControllers(namespace).Apply(ctx, rc, clients.ApplyOptions())
+	return err
+}
+
+func (replicationControllerKind) Ready(ctx context.Context, clients Clients, namespace, name string) (bool, error) {
+	rc, err := clients.Kubernetes.CoreV1().ReplicationControllers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	replicas := int32(replicasOrDefault(rc.Spec.Replicas))
+	return rc.Status.ObservedGeneration >= rc.Generation &&
+		rc.Status.ReadyReplicas == replicas &&
+		rc.Status.Replicas == replicas, nil
+}
+
+func (replicationControllerKind) PodTemplate(ctx context.Context, clients Clients, namespace, name string) (*corev1.PodTemplateSpec, error) {
+	rc, err := clients.Kubernetes.CoreV1().ReplicationControllers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if rc.Spec.Template == nil {
+		return &corev1.PodTemplateSpec{}, nil
+	}
+	return rc.Spec.Template, nil
+}
+
+// jobKind scales the parallelism of batch/v1 Jobs, and the completions of
+// Indexed Jobs along with it when elastic
+type jobKind struct{}
+
+func (jobKind) Name() string { return "job" }
+
+func (jobKind) Aliases() []string { return []string{"jobs"} }
+
+func (jobKind) List(ctx context.Context, clients Clients, namespace string) ([]string, error) {
+	jobs, err := clients.Kubernetes.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, job := range jobs.Items {
+		names = append(names, job.Name)
+	}
+	return names, nil
+}
+
+func (jobKind) Status(ctx context.Context, clients Clients, namespace string) ([]ReplicaStatus, error) {
+	jobs, err := clients.Kubernetes.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []ReplicaStatus
+	for _, job := range jobs.Items {
+		statuses = append(statuses, ReplicaStatus{
+			Name:    job.Name,
+			Desired: replicasOrDefault(job.Spec.Parallelism),
+			Ready:   int(job.Status.Active),
+		})
+	}
+	return statuses, nil
+}
+
+func (jobKind) GetReplicas(ctx context.Context, clients Clients, namespace, name string) (int, error) {
+	job, err := clients.Kubernetes.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return -1, err
+	}
+	return replicasOrDefault(job.Spec.Parallelism), nil
+}
+
+func (jobKind) Metadata(ctx context.Context, clients Clients, namespace, name string) (metav1.ObjectMeta, error) {
+	job, err := clients.Kubernetes.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return metav1.ObjectMeta{}, err
+	}
+	return job.ObjectMeta, nil
+}
+
+func (jobKind) SetReplicas(ctx context.Context, clients Clients, namespace, name string, replicas int) error {
+	job := batchv1ac.Job(name, namespace).
+		WithSpec(batchv1ac.JobSpec().WithParallelism(int32(replicas)))
+	_, err := clients.Kubernetes.BatchV1().Jobs(namespace).Apply(ctx, job, clients.ApplyOptions())
+	return err
+}
+
+// GetCompletions returns the completions of an Indexed job, the only jobs whose
+// completions can be changed
+func (jobKind) GetCompletions(ctx context.Context, clients Clients, namespace, name string) (int, error) {
+	job, err := clients.Kubernetes.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return -1, err
+	}
+
+	if job.Spec.CompletionMode == nil || *job.Spec.CompletionMode != batchv1.IndexedCompletion {
+		return -1, fmt.Errorf("completions can only be changed on Indexed jobs, job %s is NonIndexed", name)
+	}
+	if job.Spec.Completions == nil {
+		return -1, fmt.Errorf("job %s has no completions", name)
+	}
+	return int(*job.Spec.Completions), nil
+}
+
+// SetElastic sets the parallelism and completions of an Indexed job together,
+// as the API requires them to be equal when completions change
+func (jobKind) SetElastic(ctx context.Context, clients Clients, namespace, name string, replicas int) error {
+	job := batchv1ac.Job(name, namespace).
+		WithSpec(batchv1ac.JobSpec().WithParallelism(int32(replicas)).WithCompletions(int32(replicas)))
+	_, err := clients.Kubernetes.BatchV1().Jobs(namespace).Apply(ctx, job, clients.ApplyOptions())
+	return err
+}
+
+func (jobKind) Suspend(ctx context.Context, clients Clients, namespace, name string) error {
+	return applyJobSuspend(ctx, clients, namespace, name, true)
+}
+
+func (jobKind) Resume(ctx context.Context, clients Clients, namespace, name string) error {
+	return applyJobSuspend(ctx, clients, namespace, name, false)
+}
+
+func (jobKind) Ready(ctx context.Context, clients Clients, namespace, name string) (bool, error) {
+	job, err := clients.Kubernetes.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	// A finished or suspended job has nothing left to become ready
+	if job.Spec.Suspend != nil && *job.Spec.Suspend {
+		return true, nil
+	}
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == corev1.ConditionTrue {
+			return true, nil
+		}
+	}
+
+	if job.Status.Ready == nil {
+		return job.Status.Active >= int32(replicasOrDefault(job.Spec.Parallelism)), nil
+	}
+	return *job.Status.Ready >= int32(replicasOrDefault(job.Spec.Parallelism)), nil
+}
+
+func (jobKind) PodTemplate(ctx context.Context, clients Clients, namespace, name string) (*corev1.PodTemplateSpec, error) {
+	job, err := clients.Kubernetes.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &job.Spec.Template, nil
+}
+
+// cronJobKind scales the job template parallelism of batch/v1 CronJobs. Scaling
+// to zero suspends a cronjob instead, and a suspended cronjob has zero replicas.
+type cronJobKind struct{}
+
+func (cronJobKind) Name() string { return "cronjob" }
+
+func (cronJobKind) Aliases() []string { return []string{"cj", "cronjobs"} }
+
+func (cronJobKind) List(ctx context.Context, clients Clients, namespace string) ([]string, error) {
+	cronjobs, err := clients.Kubernetes.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, cronjob := range cronjobs.Items {
+		names = append(names, cronjob.Name)
+	}
+	return names, nil
+}
+
+func (cronJobKind) Status(ctx context.Context, clients Clients, namespace string) ([]ReplicaStatus, error) {
+	cronjobs, err := clients.Kubernetes.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []ReplicaStatus
+	for _, cronjob := range cronjobs.Items {
+		statuses = append(statuses, ReplicaStatus{
+			Name:    cronjob.Name,
+			Desired: cronJobReplicas(&cronjob),
+			Ready:   len(cronjob.Status.Active),
+		})
+	}
+	return statuses, nil
+}
+
+func (cronJobKind) GetReplicas(ctx context.Context, clients Clients, namespace, name string) (int, error) {
+	cronjob, err := clients.Kubernetes.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return -1, err
+	}
+	return cronJobReplicas(cronjob), nil
+}
+
+func (cronJobKind) Metadata(ctx context.Context, clients Clients, namespace, name string) (metav1.ObjectMeta, error) {
+	cronjob, err := clients.Kubernetes.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return metav1.ObjectMeta{}, err
+	}
+	return cronjob.ObjectMeta, nil
+}
+
+// SetReplicas suspends the cronjob when scaling to zero, and otherwise sets the
+// parallelism and resumes it
+func (k cronJobKind) SetReplicas(ctx context.Context, clients Clients, namespace, name string, replicas int) error {
+	if replicas == 0 {
+		return k.Suspend(ctx, clients, namespace, name)
+	}
+
+	cronjob := batchv1ac.CronJob(name, namespace).
+		WithSpec(batchv1ac.CronJobSpec().
+			WithSuspend(false).
+			WithJobTemplate(batchv1ac.JobTemplateSpec().
+				WithSpec(batchv1ac.JobSpec().WithParallelism(int32(replicas)))))
+	_, err := clients.Kubernetes.BatchV1().CronJobs(namespace).Apply(ctx, cronjob, clients.ApplyOptions())
+	return err
+}
+
+func (cronJobKind) Suspend(ctx context.Context, clients Clients, namespace, name string) error {
+	return applyCronJobSuspend(ctx, clients, namespace, name, true)
+}
+
+func (cronJobKind) Resume(ctx context.Context, clients Clients, namespace, name string) error {
+	return applyCronJobSuspend(ctx, clients, namespace, name, false)
+}
+
+func (cronJobKind) ActiveJobs(ctx context.Context, clients Clients, namespace, name string) ([]string, error) {
+	cronjob, err := clients.Kubernetes.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, active := range cronjob.Status.Active {
+		names = append(names, active.Name)
+	}
+	return names, nil
+}
+
+// Ready is always true for cronjobs, which have no pods of their own
+func (cronJobKind) Ready(ctx context.Context, clients Clients, namespace, name string) (bool, error) {
+	_, err := clients.Kubernetes.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	return err == nil, err
+}
+
+func (cronJobKind) PodTemplate(ctx context.Context, clients Clients, namespace, name string) (*corev1.PodTemplateSpec, error) {
+	cronjob, err := clients.Kubernetes.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &cronjob.Spec.JobTemplate.Spec.Template, nil
+}
+
+// cronJobReplicas returns the parallelism of a cronjob, or zero when it is suspended
+func cronJobReplicas(cronjob *batchv1.CronJob) int {
+	if cronjob.Spec.Suspend != nil && *cronjob.Spec.Suspend {
+		return 0
+	}
+	return replicasOrDefault(cronjob.Spec.JobTemplate.Spec.Parallelism)
+}
+
+// applyCronJobSuspend sets spec.suspend of a cronjob. The current parallelism is
+// applied along with it, so that it is kept if the field manager owns it.
+func applyCronJobSuspend(ctx context.Context, clients Clients, namespace, name string, suspend bool) error {
+	current, err := clients.Kubernetes.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	spec := batchv1ac.CronJobSpec().WithSuspend(suspend)
+	if parallelism := current.Spec.JobTemplate.Spec.Parallelism; parallelism != nil {
+		spec.WithJobTemplate(batchv1ac.JobTemplateSpec().
+			WithSpec(batchv1ac.JobSpec().WithParallelism(*parallelism)))
+	}
+
+	_, err = clients.Kubernetes.BatchV1().CronJobs(namespace).Apply(ctx, batchv1ac.CronJob(name, namespace).WithSpec(spec), clients.ApplyOptions())
+	return err
+}
+
+// hpaKind scales autoscaling/v2 HorizontalPodAutoscalers by their min and max
+// replicas. Setting the replicas sets minReplicas, scaling maxReplicas by the same ratio.
+type hpaKind struct{}
+
+func (hpaKind) Name() string { return "horizontalpodautoscaler" }
+
+func (hpaKind) Aliases() []string { return []string{"hpa", "horizontalpodautoscalers"} }
+
+func (hpaKind) List(ctx context.Context, clients Clients, namespace string) ([]string, error) {
+	hpas, err := clients.Kubernetes.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, hpa := range hpas.Items {
+		names = append(names, hpa.Name)
+	}
+	return names, nil
+}
+
+func (hpaKind) Status(ctx context.Context, clients Clients, namespace string) ([]ReplicaStatus, error) {
+	hpas, err := clients.Kubernetes.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []ReplicaStatus
+	for _, hpa := range hpas.Items {
+		statuses = append(statuses, ReplicaStatus{
+			Name:    hpa.Name,
+			Desired: replicasOrDefault(hpa.Spec.MinReplicas),
+			Ready:   int(hpa.Status.CurrentReplicas),
+		})
+	}
+	return statuses, nil
+}
+
+func (hpaKind) GetReplicas(ctx context.Context, clients Clients, namespace, name string) (int, error) {
+	hpa, err := clients.Kubernetes.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return -1, err
+	}
+	return replicasOrDefault(hpa.Spec.MinReplicas), nil
+}
+
+func (hpaKind) Metadata(ctx context.Context, clients Clients, namespace, name string) (metav1.ObjectMeta, error) {
+	hpa, err := clients.Kubernetes.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return metav1.ObjectMeta{}, err
+	}
+	return hpa.ObjectMeta, nil
+}
+
+func (k hpaKind) SetReplicas(ctx context.Context, clients Clients, namespace, name string, replicas int) error {
+	current, original, err := k.GetBounds(ctx, clients, namespace, name)
+	if err != nil {
+		return err
+	}
+	if original == nil {
+		original = &current
+	}
+	return k.SetBounds(ctx, clients, namespace, name, current.ScaledTo(replicas), original)
+}
+
+func (hpaKind) GetBounds(ctx context.Context, clients Clients, namespace, name string) (Bounds, *Bounds, error) {
+	hpa, err := clients.Kubernetes.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return Bounds{}, nil, err
+	}
+	return hpaBounds(hpa)
+}
+
+func (hpaKind) SetBounds(ctx context.Context, clients Clients, namespace, name string, bounds Bounds, original *Bounds) error {
+	hpa := autoscalingv2ac.HorizontalPodAutoscaler(name, namespace).
+		WithSpec(autoscalingv2ac.HorizontalPodAutoscalerSpec().
+			WithMinReplicas(int32(bounds.Min)).
+			WithMaxReplicas(int32(bounds.Max)))
+	if original != nil {
+		hpa.WithAnnotations(original.annotations())
+	}
+	_, err := clients.Kubernetes.AutoscalingV2().HorizontalPodAutoscalers(namespace).Apply(ctx, hpa, clients.ApplyOptions())
+	return err
+}
+
+func (hpaKind) Ready(ctx context.Context, clients Clients, namespace, name string) (bool, error) {
+	hpa, err := clients.Kubernetes.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	current := hpa.Status.CurrentReplicas
+	return current >= int32(replicasOrDefault(hpa.Spec.MinReplicas)) && current <= hpa.Spec.MaxReplicas, nil
+}
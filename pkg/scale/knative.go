@@ -0,0 +1,221 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Annotations on the revision template of a Knative Service bounding its replicas.
+// A max-scale of 0 means unlimited.
+const (
+	KnativeMinScaleAnnotation = "autoscaling.knative.dev/min-scale"
+	KnativeMaxScaleAnnotation = "autoscaling.knative.dev/max-scale"
+)
+
+// Knative Serving resources
+var (
+	KnativeServiceGVR  = schema.GroupVersionResource{Group: "serving.knative.dev", Version: "v1", Resource: "services"}
+	KnativeRevisionGVR = schema.GroupVersionResource{Group: "serving.knative.dev", Version: "v1", Resource: "revisions"}
+)
+
+// knativeServiceKind scales Knative Services by the min-scale and max-scale
+// annotations of their revision template, through the dynamic client. Every
+// change creates a new revision, so a service is only ready once its latest
+// revision is.
+type knativeServiceKind struct{}
+
+func (knativeServiceKind) Name() string { return "ksvc" }
+
+func (knativeServiceKind) Aliases() []string { return []string{"knativeservice", "knativeservices"} }
+
+func (knativeServiceKind) List(ctx context.Context, clients Clients, namespace string) ([]string, error) {
+	services, err := listKnativeServices(ctx, clients, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, service := range services {
+		names = append(names, service.GetName())
+	}
+	return names, nil
+}
+
+func (knativeServiceKind) Status(ctx context.Context, clients Clients, namespace string) ([]ReplicaStatus, error) {
+	services, err := listKnativeServices(ctx, clients, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []ReplicaStatus
+	for _, service := range services {
+		bounds, _, err := knativeBounds(&service)
+		if err != nil {
+			return nil, err
+		}
+
+		// The running pods belong to the latest ready revision
+		ready := 0
+		revisionName, _, _ := unstructured.NestedString(service.Object, "status", "latestReadyRevisionName")
+		if revisionName != "" {
+			revision, err := clients.Dynamic.Resource(KnativeRevisionGVR).Namespace(namespace).Get(ctx, revisionName, metav1.GetOptions{})
+			if err == nil {
+				actual, _, _ := unstructured.NestedInt64(revision.Object, "status", "actualReplicas")
+				ready = int(actual)
+			}
+		}
+
+		statuses = append(statuses, ReplicaStatus{Name: service.GetName(), Desired: bounds.Min, Ready: ready})
+	}
+	return statuses, nil
+}
+
+func (k knativeServiceKind) GetReplicas(ctx context.Context, clients Clients, namespace, name string) (int, error) {
+	bounds, _, err := k.GetBounds(ctx, clients, namespace, name)
+	if err != nil {
+		return -1, err
+	}
+	return bounds.Min, nil
+}
+
+func (knativeServiceKind) Metadata(ctx context.Context, clients Clients, namespace, name string) (metav1.ObjectMeta, error) {
+	service, err := getKnativeService(ctx, clients, namespace, name)
+	if err != nil {
+		return metav1.ObjectMeta{}, err
+	}
+
+	return metav1.ObjectMeta{
+		Name:            service.GetName(),
+		Namespace:       service.GetNamespace(),
+		Labels:          service.GetLabels(),
+		Annotations:     service.GetAnnotations(),
+		OwnerReferences: service.GetOwnerReferences(),
+	}, nil
+}
+
+func (k knativeServiceKind) SetReplicas(ctx context.Context, clients Clients, namespace, name string, replicas int) error {
+	current, original, err := k.GetBounds(ctx, clients, namespace, name)
+	if err != nil {
+		return err
+	}
+	if original == nil {
+		original = &current
+	}
+	return k.SetBounds(ctx, clients, namespace, name, current.ScaledTo(replicas), original)
+}
+
+func (knativeServiceKind) GetBounds(ctx context.Context, clients Clients, namespace, name string) (Bounds, *Bounds, error) {
+	service, err := getKnativeService(ctx, clients, namespace, name)
+	if err != nil {
+		return Bounds{}, nil, err
+	}
+	return knativeBounds(service)
+}
+
+func (knativeServiceKind) SetBounds(ctx context.Context, clients Clients, namespace, name string, bounds Bounds, original *Bounds) error {
+	if clients.Dynamic == nil {
+		return fmt.Errorf("a dynamic client is required to scale ksvcs")
+	}
+
+	service := &unstructured.Unstructured{}
+	service.SetAPIVersion(KnativeServiceGVR.GroupVersion().String())
+	service.SetKind("Service")
+	service.SetName(name)
+	service.SetNamespace(namespace)
+	if original != nil {
+		service.SetAnnotations(original.annotations())
+	}
+	err := unstructured.SetNestedStringMap(service.Object, map[string]string{
+		KnativeMinScaleAnnotation: strconv.Itoa(bounds.Min),
+		KnativeMaxScaleAnnotation: strconv.Itoa(bounds.Max),
+	}, "spec", "template", "metadata", "annotations")
+	if err != nil {
+		return err
+	}
+
+	_, err = clients.Dynamic.Resource(KnativeServiceGVR).Namespace(namespace).Apply(ctx, name, service, clients.ApplyOptions())
+	return err
+}
+
+// Ready reports whether the service is Ready with the revision created by the
+// latest change
+func (knativeServiceKind) Ready(ctx context.Context, clients Clients, namespace, name string) (bool, error) {
+	service, err := getKnativeService(ctx, clients, namespace, name)
+	if err != nil {
+		return false, err
+	}
+
+	observed, _, _ := unstructured.NestedInt64(service.Object, "status", "observedGeneration")
+	if observed < service.GetGeneration() {
+		return false, nil
+	}
+
+	latestCreated, _, _ := unstructured.NestedString(service.Object, "status", "latestCreatedRevisionName")
+	latestReady, _, _ := unstructured.NestedString(service.Object, "status", "latestReadyRevisionName")
+	if latestCreated == "" || latestCreated != latestReady {
+		return false, nil
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(service.Object, "status", "conditions")
+	for _, condition := range conditions {
+		condition, ok := condition.(map[string]any)
+		if ok && condition["type"] == "Ready" {
+			return condition["status"] == "True", nil
+		}
+	}
+	return false, nil
+}
+
+// listKnativeServices lists the Knative Services in a namespace with the dynamic
+// client, none if Knative Serving is not installed
+func listKnativeServices(ctx context.Context, clients Clients, namespace string) ([]unstructured.Unstructured, error) {
+	if clients.Dynamic == nil {
+		return nil, fmt.Errorf("a dynamic client is required to list ksvcs")
+	}
+
+	list, err := clients.Dynamic.Resource(KnativeServiceGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if apiMissing(err) {
+		// Without Knative Serving installed there are no Knative Services
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// getKnativeService reads a Knative Service with the dynamic client
+func getKnativeService(ctx context.Context, clients Clients, namespace, name string) (*unstructured.Unstructured, error) {
+	if clients.Dynamic == nil {
+		return nil, fmt.Errorf("a dynamic client is required to get ksvcs")
+	}
+	return clients.Dynamic.Resource(KnativeServiceGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// knativeBounds returns the bounds of a Knative Service from its revision
+// template and the original bounds recorded on it, nil if none. Unset
+// annotations take the Knative defaults of 0 and unlimited.
+func knativeBounds(service *unstructured.Unstructured) (Bounds, *Bounds, error) {
+	original, err := originalBounds(service.GetAnnotations())
+	if err != nil {
+		return Bounds{}, nil, fmt.Errorf("error reading ksvc %s: %v", service.GetName(), err)
+	}
+
+	annotations, _, _ := unstructured.NestedStringMap(service.Object, "spec", "template", "metadata", "annotations")
+	var bounds Bounds
+	for annotation, bound := range map[string]*int{KnativeMinScaleAnnotation: &bounds.Min, KnativeMaxScaleAnnotation: &bounds.Max} {
+		value, ok := annotations[annotation]
+		if !ok {
+			continue
+		}
+		if *bound, err = strconv.Atoi(value); err != nil {
+			return Bounds{}, nil, fmt.Errorf("invalid %s annotation on ksvc %s: %v", annotation, service.GetName(), err)
+		}
+	}
+	return bounds, original, nil
+}
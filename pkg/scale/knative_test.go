@@ -0,0 +1,155 @@
+package scale
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newKnativeService returns a Knative Service scaling between 1 and 10 replicas,
+// with its latest revision ready if ready is set
+func newKnativeService(name string, ready bool) *unstructured.Unstructured {
+	latestReady := name + "-00001"
+	if !ready {
+		latestReady = ""
+	}
+
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "serving.knative.dev/v1",
+		"kind":       "Service",
+		"metadata":   map[string]any{"name": name, "namespace": "default", "generation": int64(1)},
+		"spec": map[string]any{
+			"template": map[string]any{
+				"metadata": map[string]any{"annotations": map[string]any{
+					KnativeMinScaleAnnotation: "1",
+					KnativeMaxScaleAnnotation: "10",
+				}},
+				"spec": map[string]any{"containers": []any{map[string]any{"image": "example.com/hello"}}},
+			},
+		},
+		"status": map[string]any{
+			"observedGeneration":        int64(1),
+			"latestCreatedRevisionName": name + "-00001",
+			"latestReadyRevisionName":   latestReady,
+			"conditions":                []any{map[string]any{"type": "Ready", "status": map[bool]string{true: "True", false: "Unknown"}[ready]}},
+		},
+	}}
+}
+
+func newKnativeClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{KnativeServiceGVR: "ServiceList", KnativeRevisionGVR: "RevisionList"},
+		objects...)
+	dynamicClient.PrependReactor("patch", "services", applyAsMergePatch(dynamicClient.Tracker()))
+	return dynamicClient
+}
+
+func TestScaleKnativeService(t *testing.T) {
+	dynamicClient := newKnativeClient(newKnativeService("hello", true))
+	ctx := context.TODO()
+
+	result := NewScaler(fake.NewSimpleClientset(), dynamicClient, Options{Replicas: 2, CurrentReplicas: -1}).Scale(ctx, "ksvc", "hello", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to scale ksvc: %v", result.Err)
+	}
+	if result.PreviousReplicas != 1 || result.Replicas != 2 {
+		t.Errorf("Expected ksvc to be scaled from 1 to 2, got %d to %d", result.PreviousReplicas, result.Replicas)
+	}
+
+	service, err := dynamicClient.Resource(KnativeServiceGVR).Namespace("default").Get(ctx, "hello", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get ksvc: %v", err)
+	}
+
+	annotations, _, _ := unstructured.NestedStringMap(service.Object, "spec", "template", "metadata", "annotations")
+	if annotations[KnativeMinScaleAnnotation] != "2" || annotations[KnativeMaxScaleAnnotation] != "20" {
+		t.Errorf("Expected min-scale 2 and max-scale 20, got %v", annotations)
+	}
+	if service.GetAnnotations()[OriginalMinReplicasAnnotation] != "1" || service.GetAnnotations()[OriginalMaxReplicasAnnotation] != "10" {
+		t.Errorf("Expected original bounds to be recorded, got %v", service.GetAnnotations())
+	}
+	if containers, _, _ := unstructured.NestedSlice(service.Object, "spec", "template", "spec", "containers"); len(containers) != 1 {
+		t.Errorf("Expected the revision template to be kept, got %v", containers)
+	}
+}
+
+func TestScaleKnativeServiceUnlimitedMax(t *testing.T) {
+	service := newKnativeService("hello", true)
+	unstructured.RemoveNestedField(service.Object, "spec", "template", "metadata", "annotations", KnativeMaxScaleAnnotation)
+	dynamicClient := newKnativeClient(service)
+
+	result := NewScaler(fake.NewSimpleClientset(), dynamicClient, Options{Replicas: 3, CurrentReplicas: -1}).Scale(context.TODO(), "ksvc", "hello", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to scale ksvc: %v", result.Err)
+	}
+
+	bounds, _, err := knativeServiceKind{}.GetBounds(context.TODO(), Clients{Dynamic: dynamicClient}, "default", "hello")
+	if err != nil {
+		t.Fatalf("Failed to get ksvc bounds: %v", err)
+	}
+	if bounds != (Bounds{Min: 3, Max: 0}) {
+		t.Errorf("Expected bounds 3-unlimited, got %s", bounds)
+	}
+}
+
+func TestScaleKnativeServiceToZero(t *testing.T) {
+	service := newKnativeService("hello", true)
+	unstructured.SetNestedField(service.Object, "2", "spec", "template", "metadata", "annotations", KnativeMinScaleAnnotation)
+	dynamicClient := newKnativeClient(service)
+
+	result := NewScaler(fake.NewSimpleClientset(), dynamicClient, Options{Replicas: 0, CurrentReplicas: -1}).Scale(context.TODO(), "ksvc", "hello", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to scale ksvc to zero: %v", result.Err)
+	}
+
+	bounds, _, err := knativeServiceKind{}.GetBounds(context.TODO(), Clients{Dynamic: dynamicClient}, "default", "hello")
+	if err != nil {
+		t.Fatalf("Failed to get ksvc bounds: %v", err)
+	}
+	if bounds != (Bounds{Min: 0, Max: 10}) {
+		t.Errorf("Expected bounds 0-10, got %s", bounds)
+	}
+}
+
+func TestScaleKnativeServiceWait(t *testing.T) {
+	defer func(interval time.Duration) { readyPollInterval = interval }(readyPollInterval)
+	readyPollInterval = 10 * time.Millisecond
+
+	dynamicClient := newKnativeClient(newKnativeService("ready", true), newKnativeService("pending", false))
+	opts := Options{Replicas: 2, CurrentReplicas: -1, Wait: true, WaitTimeout: 50 * time.Millisecond}
+
+	if _, err := NewScaler(fake.NewSimpleClientset(), dynamicClient, opts).ScaleNames(context.TODO(), "ksvc", []string{"ready"}, []string{"default"}); err != nil {
+		t.Errorf("Expected ready ksvc to be waited for, got %v", err)
+	}
+
+	results, err := NewScaler(fake.NewSimpleClientset(), dynamicClient, opts).ScaleNames(context.TODO(), "ksvc", []string{"pending"}, []string{"default"})
+	if err == nil {
+		t.Error("Expected error waiting for a ksvc whose latest revision is not ready, got nil")
+	}
+	if len(results) != 1 || !results[0].Succeeded() {
+		t.Errorf("Expected ksvc to be scaled before waiting, got %+v", results)
+	}
+}
+
+func TestKnativeServicesWithoutKnative(t *testing.T) {
+	for name, err := range missingAPIErrors(KnativeServiceGVR) {
+		t.Run(name, func(t *testing.T) {
+			clients := Clients{Kubernetes: fake.NewSimpleClientset(), Dynamic: newMissingAPIClient(KnativeServiceGVR, err)}
+			kind, _ := Lookup("ksvc")
+
+			if names, err := kind.List(context.TODO(), clients, "default"); err != nil || len(names) != 0 {
+				t.Errorf("Expected no Knative Services without Knative Serving, got %v: %v", names, err)
+			}
+			if statuses, err := kind.Status(context.TODO(), clients, "default"); err != nil || len(statuses) != 0 {
+				t.Errorf("Expected no statuses without Knative Serving, got %v: %v", statuses, err)
+			}
+		})
+	}
+}
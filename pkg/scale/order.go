@@ -0,0 +1,346 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Annotations ordering the resources scaled together
+const (
+	// OrderAnnotation is an integer; resources with a lower order are scaled up
+	// first and scaled down last. Resources without it have order 0.
+	OrderAnnotation = "mscale.io/order"
+	// DependsOnAnnotation is a comma-separated list of kind/name resources in the
+	// same namespace that are scaled up before and scaled down after this one
+	DependsOnAnnotation = "mscale.io/depends-on"
+)
+
+// DefaultWaitTimeout is how long to wait for a level of ordered resources to
+// become ready when Options.WaitTimeout is not set
+const DefaultWaitTimeout = 5 * time.Minute
+
+// readyPollInterval is how often readiness is checked while waiting between levels
+var readyPollInterval = 2 * time.Second
+
+// Target identifies a single resource to scale
+type Target struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// String formats the target as kind/name in namespace
+func (t Target) String() string {
+	return fmt.Sprintf("%s/%s in namespace %s", t.Kind, t.Name, t.Namespace)
+}
+
+// orderedTarget is a target with its ordering annotations and scaling direction
+type orderedTarget struct {
+	Target
+	order     int
+	dependsOn []Target
+	down      bool
+	level     int
+}
+
+// ScaleTargets scales the targets in the order given by their OrderAnnotation
+// and DependsOnAnnotation, which only order resources in the same namespace.
+// Scale-downs run first, in descending order, followed by scale-ups in ascending
+// order. Between levels, it waits for the scaled resources to become ready, and
+// a resource that fails or does not become ready blocks the later levels of its
+// namespace only. Without annotations all targets form a single level and are
+// scaled without waiting. With Options.Wait, it finally waits for all scaled
+// resources to become ready.
+func (s *Scaler) ScaleTargets(ctx context.Context, targets []Target) ([]Result, error) {
+	return s.ScaleTargetsTo(ctx, targets, nil)
+}
+
+// ScaleTargetsTo scales the targets like ScaleTargets, each to its replicas in
+// the map, or to Options.Replicas if it has none. With Options.ResolveOwner,
+// targets owned by the same controller scale it once, to the replicas of the
+// first of them in the map.
+func (s *Scaler) ScaleTargetsTo(ctx context.Context, targets []Target, replicas map[Target]int) ([]Result, error) {
+	// Owned targets are replaced by their owner up front, so that an owner of
+	// several targets is scaled once, in its own place in the order
+	var replaced map[Target][]Target
+	if s.opts.ResolveOwner {
+		targets, replaced = s.resolveOwners(ctx, targets)
+		resolved := make(map[Target]int, len(replicas))
+		for target, count := range replicas {
+			resolved[target] = count
+		}
+		for owner, owned := range replaced {
+			if _, ok := resolved[owner]; ok {
+				continue
+			}
+			for _, target := range owned {
+				if count, ok := replicas[target]; ok {
+					resolved[owner] = count
+					break
+				}
+			}
+		}
+		replicas = resolved
+	}
+
+	ordered, err := s.orderTargets(ctx, targets, replicas)
+	if err != nil {
+		return nil, err
+	}
+
+	maxLevel := 0
+	for _, target := range ordered {
+		maxLevel = max(maxLevel, target.level)
+	}
+
+	// Levels to run, as (down, level) pairs: scale-downs in reverse, then scale-ups
+	type step struct {
+		down  bool
+		level int
+	}
+	var steps []step
+	for level := maxLevel; level >= 0; level-- {
+		steps = append(steps, step{down: true, level: level})
+	}
+	for level := 0; level <= maxLevel; level++ {
+		steps = append(steps, step{down: false, level: level})
+	}
+
+	// Namespaces are ordered independently, so each tracks the results of its
+	// previous level and whether a failure there blocks its next levels
+	var results []Result
+	blocked := map[string]error{}
+	previous := map[string][]Result{}
+	down := true
+	for _, step := range steps {
+		// Scale-ups do not depend on scale-downs
+		if step.down != down {
+			down, blocked, previous = step.down, map[string]error{}, map[string][]Result{}
+		}
+
+		var current []orderedTarget
+		var namespaces []string
+		for _, target := range ordered {
+			if target.down == step.down && target.level == step.level {
+				current = append(current, target)
+				if !slices.Contains(namespaces, target.Namespace) {
+					namespaces = append(namespaces, target.Namespace)
+				}
+			}
+		}
+
+		// Wait for the previous level of the same direction in each namespace
+		// before starting its next
+		for _, ns := range namespaces {
+			if blocked[ns] == nil && len(previous[ns]) > 0 && !s.opts.DryRun {
+				if pending, err := s.waitReady(ctx, previous[ns]); err != nil {
+					blocked[ns] = fmt.Errorf("%s/%s in namespace %s, which is ordered before it, did not become ready: %v",
+						pending.Kind, pending.Name, pending.Namespace, err)
+				}
+			}
+			previous[ns] = nil
+		}
+
+		for _, target := range current {
+			if err := ctx.Err(); err != nil {
+				return results, err
+			}
+
+			if err := blocked[target.Namespace]; err != nil {
+				results = append(results, Result{Kind: target.Kind, Namespace: target.Namespace, Name: target.Name,
+					PreviousReplicas: -1, Replicas: s.targetReplicas(target.Target, replicas), Err: fmt.Errorf("not scaled: %v", err)})
+				continue
+			}
+
+			result := s.scaleTo(ctx, target.Kind, target.Name, target.Namespace, s.targetReplicas(target.Target, replicas))
+			result.Warnings = ownerWarnings(replaced[target.Target], result.Warnings)
+			results = append(results, result)
+			previous[target.Namespace] = append(previous[target.Namespace], result)
+		}
+
+		for _, ns := range namespaces {
+			if blocked[ns] != nil {
+				continue
+			}
+			for _, result := range previous[ns] {
+				if result.Failed() {
+					blocked[ns] = fmt.Errorf("%s/%s in namespace %s, which is ordered before it, failed to scale",
+						result.Kind, result.Name, result.Namespace)
+					break
+				}
+			}
+		}
+	}
+
+	if s.opts.Wait && !s.opts.DryRun {
+		if pending, err := s.waitReady(ctx, results); err != nil {
+			return results, fmt.Errorf("%s/%s in namespace %s did not become ready: %v", pending.Kind, pending.Name, pending.Namespace, err)
+		}
+	}
+	return results, nil
+}
+
+// orderTargets reads the ordering annotations of the targets and assigns each a
+// level: one more than the highest level among the targets it depends on and
+// the targets with a lower order in the same namespace. Dependencies outside the
+// targets are ignored. Targets are scaled down when their replicas are below the current replicas.
+func (s *Scaler) orderTargets(ctx context.Context, targets []Target, replicas map[Target]int) ([]orderedTarget, error) {
+	ordered := make([]orderedTarget, 0, len(targets))
+	index := map[Target]int{}
+	for _, target := range targets {
+		orderedTarget := orderedTarget{Target: target, level: -1}
+
+		if kind, err := Lookup(target.Kind); err == nil {
+			orderedTarget.Kind = kind.Name()
+			if err := s.readOrder(ctx, kind, &orderedTarget); err != nil {
+				return nil, err
+			}
+
+			if current, err := kind.GetReplicas(ctx, s.clients, target.Namespace, target.Name); err == nil {
+				orderedTarget.down = s.targetReplicas(target, replicas) < current
+			}
+		}
+
+		index[orderedTarget.Target] = len(ordered)
+		ordered = append(ordered, orderedTarget)
+	}
+
+	// visiting marks targets on the current path, to detect dependency cycles
+	visiting := make([]bool, len(ordered))
+	var assign func(i int) error
+	assign = func(i int) error {
+		if ordered[i].level >= 0 {
+			return nil
+		}
+		if visiting[i] {
+			return fmt.Errorf("dependency cycle involving %s", ordered[i].Target)
+		}
+		visiting[i] = true
+
+		level := 0
+		var before []int
+		for _, dependency := range ordered[i].dependsOn {
+			if j, ok := index[dependency]; ok {
+				before = append(before, j)
+			}
+		}
+		for j, other := range ordered {
+			if other.Namespace == ordered[i].Namespace && other.order < ordered[i].order {
+				before = append(before, j)
+			}
+		}
+
+		for _, j := range before {
+			if err := assign(j); err != nil {
+				return err
+			}
+			level = max(level, ordered[j].level+1)
+		}
+
+		visiting[i] = false
+		ordered[i].level = level
+		return nil
+	}
+
+	for i := range ordered {
+		if err := assign(i); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// targetReplicas returns the replicas of a target in the map, or Options.Replicas if it has none
+func (s *Scaler) targetReplicas(target Target, replicas map[Target]int) int {
+	if count, ok := replicas[target]; ok {
+		return count
+	}
+	return s.opts.Replicas
+}
+
+// readOrder reads the OrderAnnotation and DependsOnAnnotation of a target. Kinds
+// without annotations, and resources that cannot be read, keep the default order.
+func (s *Scaler) readOrder(ctx context.Context, kind KindScaler, target *orderedTarget) error {
+	metadataKind, ok := kind.(MetadataKind)
+	if !ok {
+		return nil
+	}
+
+	metadata, err := metadataKind.Metadata(ctx, s.clients, target.Namespace, target.Name)
+	if err != nil {
+		return nil
+	}
+	annotations := metadata.Annotations
+
+	if value, ok := annotations[OrderAnnotation]; ok {
+		target.order, err = strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid %s annotation on %s: %v", OrderAnnotation, target.Target, err)
+		}
+	}
+
+	for _, dependency := range splitAnnotation(annotations[DependsOnAnnotation]) {
+		dependencyKind, name := kind.Name(), dependency
+		if i := strings.Index(dependency, "/"); i >= 0 {
+			dependencyKind, name = dependency[:i], dependency[i+1:]
+		}
+
+		resolved, err := Lookup(dependencyKind)
+		if err != nil {
+			return fmt.Errorf("invalid %s annotation on %s: %v", DependsOnAnnotation, target.Target, err)
+		}
+		target.dependsOn = append(target.dependsOn, Target{Kind: resolved.Name(), Namespace: target.Namespace, Name: name})
+	}
+	return nil
+}
+
+// splitAnnotation splits a comma-separated annotation value, dropping empty items
+func splitAnnotation(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// waitReady waits until the scaled resources among the results are ready, or
+// Options.WaitTimeout passes. On failure it returns the resource still pending.
+func (s *Scaler) waitReady(ctx context.Context, results []Result) (Result, error) {
+	timeout := s.opts.WaitTimeout
+	if timeout == 0 {
+		timeout = DefaultWaitTimeout
+	}
+
+	var pending Result
+	err := wait.PollUntilContextTimeout(ctx, readyPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		for _, result := range results {
+			if !result.Succeeded() {
+				continue
+			}
+
+			kind, err := Lookup(result.Kind)
+			if err != nil {
+				return false, err
+			}
+
+			ready, err := kind.Ready(ctx, s.clients, result.Namespace, result.Name)
+			if err != nil {
+				return false, err
+			}
+			if !ready {
+				pending = result
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	return pending, err
+}
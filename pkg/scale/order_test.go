@@ -0,0 +1,201 @@
+package scale
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newOrderedClientset returns a fake clientset with a postgres statefulset, an
+// api deployment depending on it and a worker deployment ordered after both
+func newOrderedClientset(replicas int32) *fake.Clientset {
+	return fake.NewSimpleClientset(
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(replicas)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "api",
+				Namespace:   "default",
+				Annotations: map[string]string{DependsOnAnnotation: "sts/postgres"},
+			},
+			Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(replicas)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "worker",
+				Namespace:   "default",
+				Annotations: map[string]string{OrderAnnotation: "10"},
+			},
+			Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(replicas)},
+		},
+	)
+}
+
+var orderedTargets = []Target{
+	{Kind: "deployment", Namespace: "default", Name: "worker"},
+	{Kind: "deployment", Namespace: "default", Name: "api"},
+	{Kind: "statefulset", Namespace: "default", Name: "postgres"},
+}
+
+func TestOrderTargets(t *testing.T) {
+	scaler := NewScaler(newOrderedClientset(1), nil, Options{Replicas: 0, CurrentReplicas: -1})
+	ordered, err := scaler.orderTargets(context.TODO(), orderedTargets, nil)
+	if err != nil {
+		t.Fatalf("Failed to order targets: %v", err)
+	}
+
+	expected := map[string]int{"worker": 2, "api": 1, "postgres": 0}
+	for _, target := range ordered {
+		if target.level != expected[target.Name] {
+			t.Errorf("Expected %s at level %d, got %d", target.Name, expected[target.Name], target.level)
+		}
+		if !target.down {
+			t.Errorf("Expected %s to be scaled down", target.Name)
+		}
+	}
+}
+
+func TestOrderTargetsCycle(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", Annotations: map[string]string{DependsOnAnnotation: "b"}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default", Annotations: map[string]string{DependsOnAnnotation: "deployment/a"}},
+		},
+	)
+
+	scaler := NewScaler(clientset, nil, Options{Replicas: 0, CurrentReplicas: -1})
+	_, err := scaler.ScaleNames(context.TODO(), "deployment", []string{"a", "b"}, []string{"default"})
+	if err == nil {
+		t.Fatal("Expected error for dependency cycle, got nil")
+	}
+}
+
+func TestScaleTargetsDownInReverseOrder(t *testing.T) {
+	clientset := newOrderedClientset(1)
+	scaler := NewScaler(clientset, nil, Options{Replicas: 0, CurrentReplicas: -1})
+
+	results, err := scaler.ScaleTargets(context.TODO(), orderedTargets)
+	if err != nil {
+		t.Fatalf("Failed to scale targets: %v", err)
+	}
+
+	var order []string
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("Failed to scale %s: %v", result.Name, result.Err)
+		}
+		order = append(order, result.Name)
+	}
+
+	expected := []string{"worker", "api", "postgres"}
+	for i := range expected {
+		if i >= len(order) || order[i] != expected[i] {
+			t.Fatalf("Expected scaling order %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestScaleTargetsUpWaitsForReadiness(t *testing.T) {
+	defer func(interval time.Duration) { readyPollInterval = interval }(readyPollInterval)
+	readyPollInterval = 10 * time.Millisecond
+
+	// The fake clientset never reports postgres ready, so the dependents are not scaled
+	clientset := newOrderedClientset(0)
+	scaler := NewScaler(clientset, nil, Options{Replicas: 1, CurrentReplicas: -1, WaitTimeout: 50 * time.Millisecond})
+
+	results, err := scaler.ScaleTargets(context.TODO(), orderedTargets)
+	if err != nil {
+		t.Fatalf("Failed to scale targets: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	if results[0].Name != "postgres" || results[0].Err != nil {
+		t.Errorf("Expected postgres to be scaled first, got %s: %v", results[0].Name, results[0].Err)
+	}
+	for _, result := range results[1:] {
+		if result.Err == nil {
+			t.Errorf("Expected %s not to be scaled before postgres is ready", result.Name)
+		}
+	}
+
+	api, err := clientset.AppsV1().Deployments("default").Get(context.TODO(), "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if *api.Spec.Replicas != 0 {
+		t.Errorf("Expected api to stay at 0 replicas, got %d", *api.Spec.Replicas)
+	}
+}
+
+func TestScaleTargetsOrdersNamespacesIndependently(t *testing.T) {
+	defer func(interval time.Duration) { readyPollInterval = interval }(readyPollInterval)
+	readyPollInterval = 10 * time.Millisecond
+
+	// postgres in team-a never becomes ready, which must not hold back team-b
+	clientset := fake.NewSimpleClientset(
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "team-a"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(0)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "team-a", Annotations: map[string]string{OrderAnnotation: "10"}},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(0)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-b", Annotations: map[string]string{OrderAnnotation: "10"}},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(0)},
+		},
+	)
+	targets := []Target{
+		{Kind: "deployment", Namespace: "team-a", Name: "api"},
+		{Kind: "statefulset", Namespace: "team-a", Name: "postgres"},
+		{Kind: "deployment", Namespace: "team-b", Name: "web"},
+	}
+
+	scaler := NewScaler(clientset, nil, Options{Replicas: 1, CurrentReplicas: -1, WaitTimeout: 50 * time.Millisecond})
+	ordered, err := scaler.orderTargets(context.TODO(), targets, nil)
+	if err != nil {
+		t.Fatalf("Failed to order targets: %v", err)
+	}
+	expected := map[string]int{"api": 1, "postgres": 0, "web": 0}
+	for _, target := range ordered {
+		if target.level != expected[target.Name] {
+			t.Errorf("Expected %s at level %d, got %d", target.Name, expected[target.Name], target.level)
+		}
+	}
+
+	results, err := scaler.ScaleTargets(context.TODO(), targets)
+	if err != nil {
+		t.Fatalf("Failed to scale targets: %v", err)
+	}
+	for _, result := range results {
+		switch result.Name {
+		case "api":
+			if result.Err == nil {
+				t.Errorf("Expected api not to be scaled before postgres is ready")
+			}
+		default:
+			if result.Err != nil {
+				t.Errorf("Failed to scale %s: %v", result.Name, result.Err)
+			}
+		}
+	}
+
+	web, err := clientset.AppsV1().Deployments("team-b").Get(context.TODO(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if *web.Spec.Replicas != 1 {
+		t.Errorf("Expected web to be scaled to 1 replica, got %d", *web.Spec.Replicas)
+	}
+}
@@ -0,0 +1,92 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ownerKinds are the controllers that resources they own are scaled through.
+// Other controllers, such as KEDA owning the HPAs of its ScaledObjects, are not
+// scaled in place of the resources they own.
+var ownerKinds = map[schema.GroupKind]string{
+	{Group: "apps", Kind: "Deployment"}: "deployment",
+	{Group: "batch", Kind: "CronJob"}:   "cronjob",
+}
+
+// controllerOwner returns the kind and name of the controller owning a resource,
+// if it is one of ownerKinds: the Deployment of a ReplicaSet or the CronJob of a
+// Job. Scaling such resources directly is reverted by their controller.
+func (s *Scaler) controllerOwner(ctx context.Context, kind KindScaler, namespace, name string) (KindScaler, string, bool) {
+	metadataKind, ok := kind.(MetadataKind)
+	if !ok {
+		return nil, "", false
+	}
+
+	metadata, err := metadataKind.Metadata(ctx, s.clients, namespace, name)
+	if err != nil {
+		return nil, "", false
+	}
+
+	owner := metav1.GetControllerOfNoCopy(&metadata)
+	if owner == nil {
+		return nil, "", false
+	}
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return nil, "", false
+	}
+	ownerKindName, ok := ownerKinds[gv.WithKind(owner.Kind).GroupKind()]
+	if !ok {
+		return nil, "", false
+	}
+	ownerKind, err := Lookup(ownerKindName)
+	if err != nil {
+		return nil, "", false
+	}
+	return ownerKind, owner.Name, true
+}
+
+// resolveOwners replaces targets owned by a controller of ownerKinds with their
+// topmost such controller, dropping duplicates. It returns the
+// resolved targets and, for each controller, the targets it replaced.
+func (s *Scaler) resolveOwners(ctx context.Context, targets []Target) ([]Target, map[Target][]Target) {
+	var resolved []Target
+	replaced := map[Target][]Target{}
+	seen := map[Target]bool{}
+	for _, target := range targets {
+		current := target
+		if kind, err := Lookup(target.Kind); err == nil {
+			target.Kind = kind.Name()
+			current = target
+			// Owner chains are short, the bound only guards against cycles
+			for range 10 {
+				owner, ownerName, ok := s.controllerOwner(ctx, kind, current.Namespace, current.Name)
+				if !ok {
+					break
+				}
+				kind, current = owner, Target{Kind: owner.Name(), Namespace: current.Namespace, Name: ownerName}
+			}
+		}
+
+		if current != target {
+			replaced[current] = append(replaced[current], target)
+		}
+		if !seen[current] {
+			seen[current] = true
+			resolved = append(resolved, current)
+		}
+	}
+	return resolved, replaced
+}
+
+// ownerWarnings prepends a warning per target replaced by its owner to the warnings
+func ownerWarnings(owned []Target, warnings []string) []string {
+	var notes []string
+	for _, target := range owned {
+		notes = append(notes, fmt.Sprintf("scaled as the owner of %s %s", target.Kind, target.Name))
+	}
+	return append(notes, warnings...)
+}
@@ -0,0 +1,144 @@
+package scale
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newOwnedReplicaSet returns a ReplicaSet in the default namespace controlled by the web deployment
+func newOwnedReplicaSet(name string, replicas int32) *appsv1.ReplicaSet {
+	controller := true
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Controller: &controller},
+			},
+		},
+		Spec: appsv1.ReplicaSetSpec{Replicas: int32Ptr(replicas)},
+	}
+}
+
+// newOwnerClientset returns a fake clientset with a web deployment of 2 replicas
+// owning a current and an old ReplicaSet, and a standalone ReplicaSet
+func newOwnerClientset() *fake.Clientset {
+	return fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+		newOwnedReplicaSet("web-new", 2),
+		newOwnedReplicaSet("web-old", 0),
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "default"},
+			Spec:       appsv1.ReplicaSetSpec{Replicas: int32Ptr(1)},
+		},
+	)
+}
+
+func TestScaleSkipsOwnedResources(t *testing.T) {
+	clientset := newOwnerClientset()
+	ctx := context.TODO()
+
+	results, err := NewScaler(clientset, nil, Options{Replicas: 3, CurrentReplicas: -1}).ScaleAll(ctx, "rs", []string{"default"})
+	if err != nil {
+		t.Fatalf("Failed to scale replicasets: %v", err)
+	}
+
+	for _, result := range results {
+		switch result.Name {
+		case "web-new", "web-old":
+			if result.Skipped != "owned by deployment web" {
+				t.Errorf("Expected %s to be skipped as owned, got %+v", result.Name, result)
+			}
+		case "standalone":
+			if !result.Succeeded() {
+				t.Errorf("Expected standalone replicaset to be scaled, got %+v", result)
+			}
+		}
+	}
+
+	replicaSet, err := clientset.AppsV1().ReplicaSets("default").Get(ctx, "web-old", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get replicaset: %v", err)
+	}
+	if *replicaSet.Spec.Replicas != 0 {
+		t.Errorf("Expected old replicaset to be left alone, got %d replicas", *replicaSet.Spec.Replicas)
+	}
+}
+
+func TestScaleResolvesOwner(t *testing.T) {
+	clientset := newOwnerClientset()
+	ctx := context.TODO()
+
+	scaler := NewScaler(clientset, nil, Options{Replicas: 3, CurrentReplicas: -1, ResolveOwner: true})
+	results, err := scaler.ScaleNames(ctx, "replicaset", []string{"web-*"}, []string{"default"})
+	if err != nil {
+		t.Fatalf("Failed to scale replicasets: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected the deployment to be scaled once, got %+v", results)
+	}
+	if results[0].Kind != "deployment" || results[0].Name != "web" || !results[0].Succeeded() {
+		t.Errorf("Expected the web deployment to be scaled, got %+v", results[0])
+	}
+	if len(results[0].Warnings) != 2 {
+		t.Errorf("Expected a warning per resolved replicaset, got %v", results[0].Warnings)
+	}
+
+	deployment, err := clientset.AppsV1().Deployments("default").Get(ctx, "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if *deployment.Spec.Replicas != 3 {
+		t.Errorf("Expected deployment to be scaled to 3, got %d", *deployment.Spec.Replicas)
+	}
+
+	result := scaler.Scale(ctx, "rs", "web-old", "default")
+	if result.Kind != "deployment" || !result.Succeeded() {
+		t.Errorf("Expected a single replicaset to resolve to its deployment, got %+v", result)
+	}
+}
+
+func TestScaleTargetsToResolvesOwnerOnce(t *testing.T) {
+	clientset := newOwnerClientset()
+	ctx := context.TODO()
+
+	targets := []Target{
+		{Kind: "replicaset", Namespace: "default", Name: "web-new"},
+		{Kind: "replicaset", Namespace: "default", Name: "web-old"},
+	}
+	replicas := map[Target]int{targets[0]: 4, targets[1]: 1}
+
+	results, err := NewScaler(clientset, nil, Options{CurrentReplicas: -1, ResolveOwner: true}).ScaleTargetsTo(ctx, targets, replicas)
+	if err != nil {
+		t.Fatalf("Failed to scale replicasets: %v", err)
+	}
+	if len(results) != 1 || results[0].Kind != "deployment" || results[0].Replicas != 4 || !results[0].Succeeded() {
+		t.Fatalf("Expected the deployment to be scaled once to the replicas of the first replicaset, got %+v", results)
+	}
+	if len(replicas) != 2 {
+		t.Errorf("Expected the replicas map to be left alone, got %v", replicas)
+	}
+}
+
+func TestScaleIgnoresOtherControllers(t *testing.T) {
+	controller := true
+	clientset := fake.NewSimpleClientset(&autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "keda-hpa-worker", Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "keda.sh/v1alpha1", Kind: "ScaledObject", Name: "worker", Controller: &controller}}},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{MinReplicas: int32Ptr(1), MaxReplicas: 10},
+	})
+
+	// KEDA owns the HPAs of its ScaledObjects without scaling through them
+	result := NewScaler(clientset, nil, Options{Replicas: 2, CurrentReplicas: -1, ResolveOwner: true}).Scale(context.TODO(), "hpa", "keda-hpa-worker", "default")
+	if result.Kind != "horizontalpodautoscaler" || !result.Succeeded() {
+		t.Errorf("Expected the HPA itself to be scaled, got %+v", result)
+	}
+}
@@ -0,0 +1,145 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// OriginalMinAvailableAnnotation records the minAvailable of a PodDisruptionBudget
+// relaxed by Options.AdjustPDB, so it can be restored when the workload is scaled up
+const OriginalMinAvailableAnnotation = "mscale.io/original-min-available"
+
+// matchingPDBs returns the PodDisruptionBudgets selecting the pods of a resource,
+// or nil for kinds without a pod template
+func (s *Scaler) matchingPDBs(ctx context.Context, kind KindScaler, namespace, name string) ([]policyv1.PodDisruptionBudget, error) {
+	templateKind, ok := kind.(PodTemplateKind)
+	if !ok {
+		return nil, nil
+	}
+
+	template, err := templateKind.PodTemplate(ctx, s.clients, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("error getting pod template: %v", err)
+	}
+
+	pdbs, err := s.clients.Kubernetes.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing PodDisruptionBudgets: %v", err)
+	}
+
+	var matching []policyv1.PodDisruptionBudget
+	for _, pdb := range pdbs.Items {
+		// A nil selector matches no pods in policy/v1
+		if pdb.Spec.Selector == nil {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			continue
+		}
+
+		if selector.Matches(labels.Set(template.Labels)) {
+			matching = append(matching, pdb)
+		}
+	}
+	return matching, nil
+}
+
+// checkPDBs verifies that scaling a resource down from current to replicas keeps
+// the minAvailable of every matching PodDisruptionBudget. A percentage is of the
+// current replicas, the expected pods, as with the disruption controller.
+// Violations are an error, a warning with Options.IgnorePDB, or relax the budget
+// with Options.AdjustPDB when scaling to zero. The returned warnings describe
+// what was let through.
+func (s *Scaler) checkPDBs(ctx context.Context, kind KindScaler, namespace, name string, current, replicas int) ([]string, error) {
+	pdbs, err := s.matchingPDBs(ctx, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var violated []policyv1.PodDisruptionBudget
+	for _, pdb := range pdbs {
+		if pdb.Spec.MinAvailable == nil {
+			continue
+		}
+
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MinAvailable, current, true)
+		if err != nil {
+			return nil, fmt.Errorf("error reading PodDisruptionBudget %s: %v", pdb.Name, err)
+		}
+		if minAvailable > replicas {
+			violated = append(violated, pdb)
+		}
+	}
+
+	if len(violated) == 0 {
+		return nil, nil
+	}
+
+	if !(s.opts.AdjustPDB && replicas == 0) && !s.opts.IgnorePDB {
+		return nil, fmt.Errorf("scaling to %d would violate PodDisruptionBudget %s requiring %s available pods",
+			replicas, violated[0].Name, violated[0].Spec.MinAvailable.String())
+	}
+
+	var warnings []string
+	for _, pdb := range violated {
+		if !s.opts.AdjustPDB || replicas != 0 {
+			warnings = append(warnings, fmt.Sprintf("PodDisruptionBudget %s requires %s available pods", pdb.Name, pdb.Spec.MinAvailable.String()))
+			continue
+		}
+
+		original := pdb.Spec.MinAvailable.String()
+		if s.opts.DryRun {
+			warnings = append(warnings, fmt.Sprintf("would relax PodDisruptionBudget %s minAvailable from %s to 0 until scaled up", pdb.Name, original))
+			continue
+		}
+
+		if pdb.Annotations == nil {
+			pdb.Annotations = map[string]string{}
+		}
+		if _, ok := pdb.Annotations[OriginalMinAvailableAnnotation]; !ok {
+			pdb.Annotations[OriginalMinAvailableAnnotation] = original
+		}
+		minAvailable := intstr.FromInt32(0)
+		pdb.Spec.MinAvailable = &minAvailable
+
+		if _, err := s.clients.Kubernetes.PolicyV1().PodDisruptionBudgets(namespace).Update(ctx, &pdb, metav1.UpdateOptions{}); err != nil {
+			return warnings, fmt.Errorf("error relaxing PodDisruptionBudget %s: %v", pdb.Name, err)
+		}
+		warnings = append(warnings, fmt.Sprintf("relaxed PodDisruptionBudget %s minAvailable from %s to 0 until scaled up", pdb.Name, original))
+	}
+	return warnings, nil
+}
+
+// restorePDBs restores the minAvailable of matching PodDisruptionBudgets that
+// were relaxed when the resource was scaled to zero
+func (s *Scaler) restorePDBs(ctx context.Context, kind KindScaler, namespace, name string) ([]string, error) {
+	pdbs, err := s.matchingPDBs(ctx, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var restored []string
+	for _, pdb := range pdbs {
+		original, ok := pdb.Annotations[OriginalMinAvailableAnnotation]
+		if !ok {
+			continue
+		}
+
+		minAvailable := intstr.Parse(original)
+		pdb.Spec.MinAvailable = &minAvailable
+		delete(pdb.Annotations, OriginalMinAvailableAnnotation)
+
+		if _, err := s.clients.Kubernetes.PolicyV1().PodDisruptionBudgets(namespace).Update(ctx, &pdb, metav1.UpdateOptions{}); err != nil {
+			return restored, fmt.Errorf("error restoring PodDisruptionBudget %s: %v", pdb.Name, err)
+		}
+		restored = append(restored, fmt.Sprintf("restored PodDisruptionBudget %s minAvailable to %s", pdb.Name, original))
+	}
+	return restored, nil
+}
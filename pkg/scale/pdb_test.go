@@ -0,0 +1,157 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newPDBClientset returns a fake clientset with a deployment of 3 replicas
+// protected by a PodDisruptionBudget requiring 2 available pods
+func newPDBClientset() *fake.Clientset {
+	minAvailable := intstr.FromInt32(2)
+	return fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: int32Ptr(3),
+				Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "api"}}},
+			},
+		},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+			},
+		},
+	)
+}
+
+func TestScaleDownRespectsPDB(t *testing.T) {
+	clientset := newPDBClientset()
+
+	// Scaling to the PDB's minAvailable is allowed
+	result := NewScaler(clientset, nil, Options{Replicas: 2, CurrentReplicas: -1}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err != nil {
+		t.Fatalf("Expected scaling to 2 to succeed, got %v", result.Err)
+	}
+
+	// Scaling below it is refused
+	result = NewScaler(clientset, nil, Options{Replicas: 1, CurrentReplicas: -1}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err == nil {
+		t.Fatal("Expected scaling to 1 to be refused, got nil")
+	}
+
+	deployment, err := clientset.AppsV1().Deployments("default").Get(context.TODO(), "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if *deployment.Spec.Replicas != 2 {
+		t.Errorf("Expected 2 replicas, got %d", *deployment.Spec.Replicas)
+	}
+
+	// With IgnorePDB it is allowed with a warning
+	result = NewScaler(clientset, nil, Options{Replicas: 1, CurrentReplicas: -1, IgnorePDB: true}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err != nil {
+		t.Fatalf("Expected scaling with IgnorePDB to succeed, got %v", result.Err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Expected 1 warning, got %v", result.Warnings)
+	}
+}
+
+func TestScaleDownRespectsPercentPDB(t *testing.T) {
+	clientset := newPDBClientset()
+	pdb, err := clientset.PolicyV1().PodDisruptionBudgets("default").Get(context.TODO(), "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get PodDisruptionBudget: %v", err)
+	}
+	minAvailable := intstr.FromString("50%")
+	pdb.Spec.MinAvailable = &minAvailable
+	if _, err := clientset.PolicyV1().PodDisruptionBudgets("default").Update(context.TODO(), pdb, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update PodDisruptionBudget: %v", err)
+	}
+
+	// 50% of the 3 current pods, rounded up, must stay available
+	for _, replicas := range []int{1, 0} {
+		result := NewScaler(clientset, nil, Options{Replicas: replicas, CurrentReplicas: -1}).Scale(context.TODO(), "deployment", "api", "default")
+		if result.Err == nil {
+			t.Errorf("Expected scaling to %d to be refused, got nil", replicas)
+		}
+	}
+
+	result := NewScaler(clientset, nil, Options{Replicas: 2, CurrentReplicas: -1}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err != nil {
+		t.Errorf("Expected scaling to 2 to succeed, got %v", result.Err)
+	}
+}
+
+func TestScaleToZeroAdjustsPDB(t *testing.T) {
+	clientset := newPDBClientset()
+
+	result := NewScaler(clientset, nil, Options{Replicas: 0, CurrentReplicas: -1, AdjustPDB: true}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err != nil {
+		t.Fatalf("Expected scaling with AdjustPDB to succeed, got %v", result.Err)
+	}
+
+	pdb, err := clientset.PolicyV1().PodDisruptionBudgets("default").Get(context.TODO(), "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get PodDisruptionBudget: %v", err)
+	}
+	if pdb.Spec.MinAvailable.IntValue() != 0 {
+		t.Errorf("Expected minAvailable 0, got %s", pdb.Spec.MinAvailable.String())
+	}
+	if pdb.Annotations[OriginalMinAvailableAnnotation] != "2" {
+		t.Errorf("Expected original minAvailable 2 to be recorded, got %q", pdb.Annotations[OriginalMinAvailableAnnotation])
+	}
+
+	// Scaling up again restores the budget
+	result = NewScaler(clientset, nil, Options{Replicas: 3, CurrentReplicas: -1}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err != nil {
+		t.Fatalf("Expected scaling up to succeed, got %v", result.Err)
+	}
+
+	pdb, err = clientset.PolicyV1().PodDisruptionBudgets("default").Get(context.TODO(), "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get PodDisruptionBudget: %v", err)
+	}
+	if pdb.Spec.MinAvailable.IntValue() != 2 {
+		t.Errorf("Expected minAvailable 2 to be restored, got %s", pdb.Spec.MinAvailable.String())
+	}
+	if _, ok := pdb.Annotations[OriginalMinAvailableAnnotation]; ok {
+		t.Error("Expected original minAvailable annotation to be removed")
+	}
+}
+
+func TestScaleToZeroRestoresPDBOnFailure(t *testing.T) {
+	clientset := newPDBClientset()
+	clientset.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("patch refused")
+	})
+
+	result := NewScaler(clientset, nil, Options{Replicas: 0, CurrentReplicas: -1, AdjustPDB: true}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err == nil {
+		t.Fatal("Expected scaling to fail, got nil")
+	}
+
+	pdb, err := clientset.PolicyV1().PodDisruptionBudgets("default").Get(context.TODO(), "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get PodDisruptionBudget: %v", err)
+	}
+	if pdb.Spec.MinAvailable.IntValue() != 2 {
+		t.Errorf("Expected minAvailable 2 to be restored, got %s", pdb.Spec.MinAvailable.String())
+	}
+	if _, ok := pdb.Annotations[OriginalMinAvailableAnnotation]; ok {
+		t.Error("Expected original minAvailable annotation to be removed")
+	}
+}
@@ -0,0 +1,184 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podResources returns the resources one pod created from the template counts
+// against a ResourceQuota, keyed by quota resource name: pods, requests.cpu,
+// limits.memory, ... Containers without requests or limits get the defaults of
+// the namespace's LimitRanges, as the API server would apply them.
+func podResources(template *corev1.PodTemplateSpec, limitRanges []corev1.LimitRange) corev1.ResourceList {
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, container := range template.Spec.Containers {
+		containerRequests, containerLimits := containerResources(container, limitRanges)
+		addResources(requests, containerRequests)
+		addResources(limits, containerLimits)
+	}
+
+	// Init containers run one at a time before the app containers, so only the largest counts
+	for _, container := range template.Spec.InitContainers {
+		containerRequests, containerLimits := containerResources(container, limitRanges)
+		maxResources(requests, containerRequests)
+		maxResources(limits, containerLimits)
+	}
+
+	addResources(requests, template.Spec.Overhead)
+	addResources(limits, template.Spec.Overhead)
+
+	result := corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")}
+	for name, quantity := range requests {
+		result[corev1.ResourceName("requests."+string(name))] = quantity
+	}
+	for name, quantity := range limits {
+		result[corev1.ResourceName("limits."+string(name))] = quantity
+	}
+	return result
+}
+
+// containerResources returns the requests and limits of a container after
+// applying the defaults of the LimitRanges
+func containerResources(container corev1.Container, limitRanges []corev1.LimitRange) (corev1.ResourceList, corev1.ResourceList) {
+	requests := container.Resources.Requests.DeepCopy()
+	limits := container.Resources.Limits.DeepCopy()
+	if requests == nil {
+		requests = corev1.ResourceList{}
+	}
+	if limits == nil {
+		limits = corev1.ResourceList{}
+	}
+
+	for _, limitRange := range limitRanges {
+		for _, item := range limitRange.Spec.Limits {
+			if item.Type != corev1.LimitTypeContainer {
+				continue
+			}
+			for name, quantity := range item.Default {
+				if _, ok := limits[name]; !ok {
+					limits[name] = quantity
+				}
+			}
+			for name, quantity := range item.DefaultRequest {
+				if _, ok := requests[name]; !ok {
+					requests[name] = quantity
+				}
+			}
+		}
+	}
+
+	// A container with a limit but no request is given a request equal to the limit
+	for name, quantity := range limits {
+		if _, ok := requests[name]; !ok {
+			requests[name] = quantity
+		}
+	}
+	return requests, limits
+}
+
+// addResources adds the quantities of add to total
+func addResources(total, add corev1.ResourceList) {
+	for name, quantity := range add {
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+}
+
+// maxResources raises the quantities of total to those of other where larger
+func maxResources(total, other corev1.ResourceList) {
+	for name, quantity := range other {
+		if current, ok := total[name]; !ok || quantity.Cmp(current) > 0 {
+			total[name] = quantity
+		}
+	}
+}
+
+// quotaResourceName maps the short quota resource names to the per-pod names used by podResources
+func quotaResourceName(name corev1.ResourceName) corev1.ResourceName {
+	switch name {
+	case corev1.ResourceCPU:
+		return corev1.ResourceRequestsCPU
+	case corev1.ResourceMemory:
+		return corev1.ResourceRequestsMemory
+	case corev1.ResourceEphemeralStorage:
+		return corev1.ResourceRequestsEphemeralStorage
+	case "count/pods":
+		return corev1.ResourcePods
+	default:
+		return name
+	}
+}
+
+// checkQuota verifies that the pods added by scaling a resource up from previous
+// to replicas fit in the remaining ResourceQuotas of the namespace. Exceeding a
+// quota is an error, or a warning with Options.IgnoreQuota. The check is
+// advisory, so quotas that cannot be read produce a warning instead of an error.
+func (s *Scaler) checkQuota(ctx context.Context, kind KindScaler, namespace, name string, previous, replicas int) ([]string, error) {
+	templateKind, ok := kind.(PodTemplateKind)
+	if !ok {
+		return nil, nil
+	}
+
+	quotas, err := s.clients.Kubernetes.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []string{fmt.Sprintf("skipped ResourceQuota check: %v", err)}, nil
+	}
+	if len(quotas.Items) == 0 {
+		return nil, nil
+	}
+
+	limitRanges, err := s.clients.Kubernetes.CoreV1().LimitRanges(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return []string{fmt.Sprintf("skipped ResourceQuota check: %v", err)}, nil
+	}
+
+	template, err := templateKind.PodTemplate(ctx, s.clients, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("error getting pod template: %v", err)
+	}
+	perPod := podResources(template, limitRanges.Items)
+
+	var exceeded []string
+	for _, quota := range quotas.Items {
+		// Scoped quotas only apply to some pods, which is not worth guessing here
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+
+		resourceNames := make([]string, 0, len(quota.Status.Hard))
+		for resourceName := range quota.Status.Hard {
+			resourceNames = append(resourceNames, string(resourceName))
+		}
+		sort.Strings(resourceNames)
+
+		for _, resourceName := range resourceNames {
+			quantity, ok := perPod[quotaResourceName(corev1.ResourceName(resourceName))]
+			if !ok {
+				continue
+			}
+
+			needed := quantity.DeepCopy()
+			needed.Mul(int64(replicas - previous))
+
+			available := quota.Status.Hard[corev1.ResourceName(resourceName)].DeepCopy()
+			available.Sub(quota.Status.Used[corev1.ResourceName(resourceName)])
+
+			if needed.Cmp(available) > 0 {
+				exceeded = append(exceeded, fmt.Sprintf("ResourceQuota %s has %s %s left, %s needed",
+					quota.Name, available.String(), resourceName, needed.String()))
+			}
+		}
+	}
+
+	if len(exceeded) > 0 && !s.opts.IgnoreQuota {
+		return nil, fmt.Errorf("scaling to %d would exceed quota: %s", replicas, strings.Join(exceeded, "; "))
+	}
+	return exceeded, nil
+}
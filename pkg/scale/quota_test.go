@@ -0,0 +1,113 @@
+package scale
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestScaleUpRespectsResourceQuota(t *testing.T) {
+	// A deployment whose container has no requests, defaulted to 500m CPU by a
+	// LimitRange, in a namespace with 1 CPU and 10 pods left in its quota
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: int32Ptr(0),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "api"}}},
+				},
+			},
+		},
+		&corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "default"},
+			Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+				Type:           corev1.LimitTypeContainer,
+				DefaultRequest: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+			}}},
+		},
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "default"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{
+					corev1.ResourceRequestsCPU: resource.MustParse("2"),
+					corev1.ResourcePods:        resource.MustParse("10"),
+				},
+				Used: corev1.ResourceList{
+					corev1.ResourceRequestsCPU: resource.MustParse("1"),
+					corev1.ResourcePods:        resource.MustParse("0"),
+				},
+			},
+		},
+	)
+
+	// 3 pods need 1500m CPU, more than is left
+	result := NewScaler(clientset, nil, Options{Replicas: 3, CurrentReplicas: -1}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err == nil {
+		t.Fatal("Expected scaling to 3 to be refused, got nil")
+	}
+
+	// 2 pods need exactly the 1 CPU left
+	result = NewScaler(clientset, nil, Options{Replicas: 2, CurrentReplicas: -1}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err != nil {
+		t.Fatalf("Expected scaling to 2 to succeed, got %v", result.Err)
+	}
+
+	// With IgnoreQuota scaling up further is allowed with a warning
+	result = NewScaler(clientset, nil, Options{Replicas: 5, CurrentReplicas: -1, IgnoreQuota: true}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err != nil {
+		t.Fatalf("Expected scaling with IgnoreQuota to succeed, got %v", result.Err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Expected 1 warning, got %v", result.Warnings)
+	}
+}
+
+func TestPodResources(t *testing.T) {
+	template := &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{
+				Name: "migrate",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				},
+			}},
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					},
+				},
+				{
+					Name: "sidecar",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("100m"),
+							corev1.ResourceMemory: resource.MustParse("128Mi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	resources := podResources(template, nil)
+	expected := map[corev1.ResourceName]string{
+		corev1.ResourcePods:           "1",
+		corev1.ResourceRequestsCPU:    "1100m",
+		corev1.ResourceRequestsMemory: "1Gi",
+		corev1.ResourceLimitsCPU:      "1",
+	}
+	for name, quantity := range expected {
+		actual, ok := resources[name]
+		if !ok || actual.Cmp(resource.MustParse(quantity)) != 0 {
+			t.Errorf("Expected %s %s, got %s", name, quantity, actual.String())
+		}
+	}
+}
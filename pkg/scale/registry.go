@@ -0,0 +1,177 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// FieldManager is the server-side apply field manager owning the fields set by kinds
+const FieldManager = "kubectl-mscale"
+
+// Clients bundles the clients available to a KindScaler
+type Clients struct {
+	Kubernetes kubernetes.Interface
+	// Dynamic is used for kinds without a typed client and may be nil
+	Dynamic dynamic.Interface
+	// ForceConflicts takes ownership of fields managed by others when applying
+	ForceConflicts bool
+}
+
+// ApplyOptions returns the options kinds use to server-side apply the fields
+// they set, such as spec.replicas, as FieldManager
+func (c Clients) ApplyOptions() metav1.ApplyOptions {
+	return metav1.ApplyOptions{FieldManager: FieldManager, Force: c.ForceConflicts}
+}
+
+// apiMissing reports whether err means the API of a kind is not served by the
+// cluster, such as a custom resource whose CRD is not installed
+func apiMissing(err error) bool {
+	return apierrors.IsNotFound(err) || meta.IsNoMatchError(err)
+}
+
+// KindScaler implements scaling for a single resource kind. Built-in kinds and
+// third-party kinds are registered the same way, with Register.
+type KindScaler interface {
+	// Name is the singular, lower-case resource type, e.g. deployment
+	Name() string
+	// Aliases are the other names accepted for the kind, e.g. deploy and deployments
+	Aliases() []string
+	// List returns the names of all resources of the kind in a namespace
+	List(ctx context.Context, clients Clients, namespace string) ([]string, error)
+	// Status returns the desired and ready replicas of all resources of the kind in a namespace
+	Status(ctx context.Context, clients Clients, namespace string) ([]ReplicaStatus, error)
+	// GetReplicas returns the current desired replica count of a resource
+	GetReplicas(ctx context.Context, clients Clients, namespace, name string) (int, error)
+	// SetReplicas sets the desired replica count of a resource, preferably by
+	// server-side applying only the replica field with Clients.ApplyOptions
+	SetReplicas(ctx context.Context, clients Clients, namespace, name string, replicas int) error
+	// Ready reports whether a resource has reached its desired replica count
+	Ready(ctx context.Context, clients Clients, namespace, name string) (bool, error)
+}
+
+// PodTemplateKind is implemented by kinds whose resources create pods from a
+// template. Checks that depend on the pods, such as PodDisruptionBudgets, are
+// skipped for kinds that do not implement it.
+type PodTemplateKind interface {
+	KindScaler
+	// PodTemplate returns the template the pods of a resource are created from
+	PodTemplate(ctx context.Context, clients Clients, namespace, name string) (*corev1.PodTemplateSpec, error)
+}
+
+// MetadataKind is implemented by kinds that can read the metadata of their
+// resources. Ordering annotations and GitOps ownership are ignored for kinds
+// that do not implement it.
+type MetadataKind interface {
+	KindScaler
+	// Metadata returns the labels, annotations and owners of a resource
+	Metadata(ctx context.Context, clients Clients, namespace, name string) (metav1.ObjectMeta, error)
+}
+
+// BoundsKind is implemented by kinds that autoscale between min and max replicas,
+// such as HorizontalPodAutoscalers. The Scaler sets their bounds instead of their
+// replicas and records the original bounds on the resource, so they can be restored.
+type BoundsKind interface {
+	KindScaler
+	// GetBounds returns the bounds of a resource and the original bounds recorded on it, nil if none
+	GetBounds(ctx context.Context, clients Clients, namespace, name string) (Bounds, *Bounds, error)
+	// SetBounds sets the bounds of a resource and records the original bounds on
+	// it, removing the record if nil
+	SetBounds(ctx context.Context, clients Clients, namespace, name string, bounds Bounds, original *Bounds) error
+}
+
+// ResumableKind is implemented by kinds that hold their resources at the given
+// replicas by pausing an autoscaler, such as KEDA ScaledObjects
+type ResumableKind interface {
+	KindScaler
+	// Resume hands a resource back to its autoscaler
+	Resume(ctx context.Context, clients Clients, namespace, name string) error
+}
+
+// SuspendableKind is implemented by kinds that can be suspended without changing
+// their replicas, such as CronJobs. Resume undoes Suspend.
+type SuspendableKind interface {
+	ResumableKind
+	// Suspend stops a resource from creating new pods
+	Suspend(ctx context.Context, clients Clients, namespace, name string) error
+}
+
+// ElasticKind is implemented by kinds whose completions can be scaled together
+// with their replicas, such as Indexed Jobs
+type ElasticKind interface {
+	KindScaler
+	// GetCompletions returns the completions of a resource, or an error if they cannot be changed
+	GetCompletions(ctx context.Context, clients Clients, namespace, name string) (int, error)
+	// SetElastic sets both the replicas and completions of a resource to replicas
+	SetElastic(ctx context.Context, clients Clients, namespace, name string, replicas int) error
+}
+
+// JobOwnerKind is implemented by kinds that spawn Jobs, such as CronJobs
+type JobOwnerKind interface {
+	KindScaler
+	// ActiveJobs returns the names of the currently running Jobs of a resource
+	ActiveJobs(ctx context.Context, clients Clients, namespace, name string) ([]string, error)
+}
+
+// ReplicaStatus is the desired and ready replica count of a single resource
+type ReplicaStatus struct {
+	Name    string
+	Desired int
+	Ready   int
+	// Warning is set instead of the replica counts when the status of the
+	// resource cannot be read, without failing the whole listing
+	Warning string
+}
+
+var (
+	registryMu sync.RWMutex
+	kinds      []KindScaler
+	kindNames  = map[string]KindScaler{}
+)
+
+// Register adds a kind to the registry. It panics if the name or one of the
+// aliases is already registered, as that is a programming error.
+func Register(kind KindScaler) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	names := append([]string{kind.Name()}, kind.Aliases()...)
+	for _, name := range names {
+		if existing, ok := kindNames[strings.ToLower(name)]; ok {
+			panic(fmt.Sprintf("scale: %s is already registered for kind %s", name, existing.Name()))
+		}
+	}
+
+	for _, name := range names {
+		kindNames[strings.ToLower(name)] = kind
+	}
+	kinds = append(kinds, kind)
+}
+
+// Lookup returns the kind registered under a name or alias
+func Lookup(resourceType string) (KindScaler, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	kind, ok := kindNames[strings.ToLower(resourceType)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource type: %s", resourceType)
+	}
+	return kind, nil
+}
+
+// Kinds returns all registered kinds in registration order
+func Kinds() []KindScaler {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return append([]KindScaler(nil), kinds...)
+}
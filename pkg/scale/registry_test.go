@@ -0,0 +1,100 @@
+package scale
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// widgetKind is an in-memory kind used to test registration of third-party kinds
+type widgetKind struct {
+	replicas map[string]int
+}
+
+func (w *widgetKind) Name() string { return "widget" }
+
+func (w *widgetKind) Aliases() []string { return []string{"wd", "widgets"} }
+
+func (w *widgetKind) List(ctx context.Context, clients Clients, namespace string) ([]string, error) {
+	var names []string
+	for name := range w.replicas {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (w *widgetKind) Status(ctx context.Context, clients Clients, namespace string) ([]ReplicaStatus, error) {
+	var statuses []ReplicaStatus
+	for name, replicas := range w.replicas {
+		statuses = append(statuses, ReplicaStatus{Name: name, Desired: replicas, Ready: replicas})
+	}
+	return statuses, nil
+}
+
+func (w *widgetKind) GetReplicas(ctx context.Context, clients Clients, namespace, name string) (int, error) {
+	return w.replicas[name], nil
+}
+
+func (w *widgetKind) SetReplicas(ctx context.Context, clients Clients, namespace, name string, replicas int) error {
+	w.replicas[name] = replicas
+	return nil
+}
+
+func (w *widgetKind) Ready(ctx context.Context, clients Clients, namespace, name string) (bool, error) {
+	return true, nil
+}
+
+func TestRegisterCustomKind(t *testing.T) {
+	widgets := &widgetKind{replicas: map[string]int{"gear": 1}}
+	Register(widgets)
+
+	kind, err := Lookup("WD")
+	if err != nil {
+		t.Fatalf("Failed to look up widget by alias: %v", err)
+	}
+
+	if kind.Name() != "widget" {
+		t.Errorf("Expected widget, got %s", kind.Name())
+	}
+
+	// Scale the custom kind through the Scaler, like any built-in kind
+	scaler := NewScaler(fake.NewSimpleClientset(), nil, Options{Replicas: 4, CurrentReplicas: 1})
+	results, err := scaler.ScaleAll(context.TODO(), "widgets", []string{"default"})
+	if err != nil {
+		t.Fatalf("Failed to scale widgets: %v", err)
+	}
+
+	if len(results) != 1 || !results[0].Succeeded() || results[0].PreviousReplicas != 1 {
+		t.Errorf("Expected one successful result from 1 replica, got %+v", results)
+	}
+
+	if widgets.replicas["gear"] != 4 {
+		t.Errorf("Expected 4 replicas, got %d", widgets.replicas["gear"])
+	}
+}
+
+func TestRegisterDuplicateAliasPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic when registering a duplicate alias, got none")
+		}
+	}()
+
+	Register(&duplicateDeployKind{})
+}
+
+// duplicateDeployKind reuses the deploy alias of the built-in deployment kind
+type duplicateDeployKind struct {
+	widgetKind
+}
+
+func (d *duplicateDeployKind) Name() string { return "duplicate" }
+
+func (d *duplicateDeployKind) Aliases() []string { return []string{"deploy"} }
+
+func TestLookupUnsupportedKind(t *testing.T) {
+	if _, err := Lookup("pod"); err == nil {
+		t.Error("Expected error for unsupported kind, got nil")
+	}
+}
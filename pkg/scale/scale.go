@@ -0,0 +1,682 @@
+// Package scale scales Kubernetes workloads across multiple namespaces.
+//
+// A Scaler is created with injected clients and Options and returns a Result
+// per resource instead of printing, so it can be embedded in other tools:
+//
+//	scaler := scale.NewScaler(clientset, dynamicClient, scale.Options{Replicas: 0, CurrentReplicas: -1})
+//	results, err := scaler.ScaleAll(ctx, "deployment", []string{"staging", "dev"})
+package scale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Options configures how a Scaler scales resources
+type Options struct {
+	// Replicas is the desired replica count, or for kinds with bounds the min
+	// replicas. It may be negative for kinds with bounds when MinReplicas,
+	// MaxReplicas or RestoreBounds is set.
+	Replicas int
+	// CurrentReplicas is a precondition on the current replica count, -1 to disable it
+	CurrentReplicas int
+	// DefaultNamespace is used when no namespaces are given and for objects without a namespace
+	DefaultNamespace string
+	// ExcludeNamespaces are glob patterns of namespaces that are never scaled
+	ExcludeNamespaces []string
+	// ExcludeNames are glob patterns of resource names that are never scaled
+	ExcludeNames []string
+	// NameRegex is a regular expression that listed resource names must match, empty to match all
+	NameRegex string
+	// IgnorePDB scales down even when a PodDisruptionBudget would be violated, with a warning
+	IgnorePDB bool
+	// AdjustPDB relaxes violated PodDisruptionBudgets when scaling to zero and
+	// restores them when the resource is scaled up again
+	AdjustPDB bool
+	// IgnoreQuota scales up even when a ResourceQuota would be exceeded, with a warning
+	IgnoreQuota bool
+	// DryRun runs all checks and reports the results without changing anything
+	DryRun bool
+	// ForceConflicts takes ownership of the replica field when another field manager
+	// applied it. Fields set by another field manager with an update are always taken over.
+	ForceConflicts bool
+	// WaitTimeout is how long to wait for each level of ordered resources to become
+	// ready before scaling the next, and for Wait, DefaultWaitTimeout if zero
+	WaitTimeout time.Duration
+	// Wait waits for the scaled resources to become ready after scaling
+	Wait bool
+	// SkipGitOps skips resources reconciled by Argo CD or Flux instead of warning about them
+	SkipGitOps bool
+	// SuspendGitOps suspends the Flux object or disables Argo CD automated sync
+	// reconciling a resource before scaling it, so the change is not reverted. It
+	// is resumed once the resources are scaled back to their previous replicas.
+	SuspendGitOps bool
+	// ResumeGitOps undoes SuspendGitOps after scaling a resource
+	ResumeGitOps bool
+	// ArgoCDNamespace is the namespace of Argo CD Applications, DefaultArgoCDNamespace if empty
+	ArgoCDNamespace string
+	// HPAMode is how resources targeted by a HorizontalPodAutoscaler are scaled, HPAWarn if empty
+	HPAMode HPAMode
+	// MinReplicas and MaxReplicas set the bounds of kinds with bounds, such as
+	// HPAs, as a count or relative to the current bound, e.g. +2; empty to leave unset
+	MinReplicas string
+	MaxReplicas string
+	// IndependentBounds leaves a bound that is not set as it is, instead of
+	// keeping its ratio to the other bound
+	IndependentBounds bool
+	// RestoreBounds restores the bounds recorded before mscale first changed them
+	RestoreBounds bool
+	// Resume hands resources of resumable kinds, such as KEDA ScaledObjects, back
+	// to their autoscaler, and resumes suspended kinds, instead of scaling them
+	Resume bool
+	// Suspend suspends resources of suspendable kinds, such as CronJobs, instead of scaling them
+	Suspend bool
+	// Completions scales the completions of elastic kinds, such as Indexed Jobs,
+	// to the replicas along with them
+	Completions bool
+	// ResolveOwner scales the controller owning a resource, such as the Deployment
+	// of a ReplicaSet, instead of skipping the resource
+	ResolveOwner bool
+	// ActiveJobs is what happens to the running Jobs of a CronJob when it is
+	// suspended or scaled to zero, ActiveJobsKeep if empty
+	ActiveJobs ActiveJobsPolicy
+}
+
+// Result is the outcome of scaling a single resource
+type Result struct {
+	Kind      string
+	Namespace string
+	// Name is empty when the result applies to the whole namespace
+	Name string
+	// PreviousReplicas is the replica count before scaling, -1 if it could not be read
+	PreviousReplicas int
+	Replicas         int
+	// Skipped is the reason the resource was deliberately left alone, empty otherwise
+	Skipped string
+	// Resumed is set when the resource was handed back to its autoscaler instead of scaled
+	Resumed bool
+	// Suspended is set when the resource was suspended instead of scaled
+	Suspended bool
+	// Warnings describe safety checks that were overridden or adjusted while scaling
+	Warnings []string
+	Err      error
+}
+
+// Succeeded reports whether the resource was scaled
+func (r Result) Succeeded() bool {
+	return r.Err == nil && r.Skipped == ""
+}
+
+// Failed reports whether scaling the resource failed
+func (r Result) Failed() bool {
+	return r.Err != nil
+}
+
+// Scaler scales resources of the supported kinds across namespaces
+type Scaler struct {
+	clients   Clients
+	opts      Options
+	nameRegex *regexp.Regexp
+}
+
+// NewScaler creates a Scaler using the given clients. The dynamic client is
+// used for kinds that have no typed client and may be nil. The options should
+// be checked with Options.Validate first; an invalid NameRegex matches all names.
+func NewScaler(clientset kubernetes.Interface, dynamicClient dynamic.Interface, opts Options) *Scaler {
+	if opts.DefaultNamespace == "" {
+		opts.DefaultNamespace = metav1.NamespaceDefault
+	}
+
+	s := &Scaler{
+		clients: Clients{Kubernetes: clientset, Dynamic: dynamicClient, ForceConflicts: opts.ForceConflicts},
+		opts:    opts,
+	}
+	if opts.NameRegex != "" {
+		s.nameRegex, _ = regexp.Compile(opts.NameRegex)
+	}
+	return s
+}
+
+// apply runs a change that server-side applies fields with the clients. Fields
+// last set by another field manager with a plain update, such as kubectl create
+// or edit, are taken over by retrying with force, while fields applied by
+// another field manager, such as a GitOps controller, need ForceConflicts.
+func (s *Scaler) apply(change func(clients Clients) error) error {
+	err := change(s.clients)
+	if err == nil || s.clients.ForceConflicts || !updateConflicts(err) {
+		return err
+	}
+
+	forced := s.clients
+	forced.ForceConflicts = true
+	return change(forced)
+}
+
+// updateConflicts reports whether err is an apply conflict only with field
+// managers that set the fields with an update
+func updateConflicts(err error) bool {
+	var status apierrors.APIStatus
+	if !apierrors.IsConflict(err) || !errors.As(err, &status) {
+		return false
+	}
+
+	details := status.Status().Details
+	if details == nil || len(details.Causes) == 0 {
+		return false
+	}
+	for _, cause := range details.Causes {
+		// Updating managers are reported with the API version they used, e.g.
+		// conflict with "kubectl-create" using apps/v1
+		if cause.Type != metav1.CauseTypeFieldManagerConflict || !strings.Contains(cause.Message, " using ") {
+			return false
+		}
+	}
+	return true
+}
+
+// ScaleFile scales resources defined in a YAML or JSON stream, in the order
+// given by their ordering annotations. Nothing is scaled if the stream cannot be decoded.
+func (s *Scaler) ScaleFile(ctx context.Context, r io.Reader) ([]Result, error) {
+	var targets []Target
+
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(obj); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("error decoding file: %v", err)
+		}
+
+		if obj.GetKind() == "" {
+			continue
+		}
+
+		// Get the resource type and name
+		namespace := obj.GetNamespace()
+		if namespace == "" {
+			namespace = s.opts.DefaultNamespace
+		}
+
+		targets = append(targets, Target{Kind: strings.ToLower(obj.GetKind()), Namespace: namespace, Name: obj.GetName()})
+	}
+
+	return s.ScaleTargets(ctx, targets)
+}
+
+// ScaleNames scales the named resources of a type in each of the namespaces,
+// in the order given by their ordering annotations. Names and namespaces may be
+// glob patterns, which are expanded against the resources and namespaces that exist.
+func (s *Scaler) ScaleNames(ctx context.Context, resourceType string, names, namespaces []string) ([]Result, error) {
+	kind, err := Lookup(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]ResourceRef, 0, len(names))
+	for _, name := range names {
+		refs = append(refs, ResourceRef{Kind: kind.Name(), Name: name})
+	}
+	return s.ScaleRefs(ctx, refs, namespaces)
+}
+
+// ScaleRefs scales resources of any kinds in each of the namespaces, ordered
+// together by their ordering annotations. Names and namespaces may be glob
+// patterns, which are expanded against the resources and namespaces that exist.
+func (s *Scaler) ScaleRefs(ctx context.Context, refs []ResourceRef, namespaces []string) ([]Result, error) {
+	// Names are grouped by kind, in the order kinds are first given
+	var kinds []KindScaler
+	names := map[string][]string{}
+	for _, ref := range refs {
+		kind, err := Lookup(ref.Kind)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := names[kind.Name()]; !ok {
+			kinds = append(kinds, kind)
+		}
+		names[kind.Name()] = append(names[kind.Name()], ref.Name)
+	}
+
+	namespaces, err := s.ResolveNamespaces(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	var targets []Target
+	for _, ns := range namespaces {
+		for _, kind := range kinds {
+			if reason := s.namespaceExcluded(ns); reason != "" {
+				results = append(results, Result{Kind: kind.Name(), Namespace: ns, PreviousReplicas: -1, Replicas: s.opts.Replicas, Skipped: reason})
+				continue
+			}
+
+			nsNames := names[kind.Name()]
+			if slices.ContainsFunc(nsNames, isPattern) {
+				existing, err := kind.List(ctx, s.clients, ns)
+				if err != nil {
+					results = append(results, Result{Kind: kind.Name(), Namespace: ns, PreviousReplicas: -1, Replicas: s.opts.Replicas,
+						Err: fmt.Errorf("error listing %ss: %v", kind.Name(), err)})
+					continue
+				}
+				nsNames = expandPatterns(nsNames, existing)
+			}
+
+			for _, name := range nsNames {
+				targets = append(targets, Target{Kind: kind.Name(), Namespace: ns, Name: name})
+			}
+		}
+	}
+
+	scaled, err := s.ScaleTargets(ctx, targets)
+	return append(results, scaled...), err
+}
+
+// ScaleAll scales all resources of a type in each of the namespaces, in the
+// order given by their ordering annotations, restricted to names matching
+// Options.NameRegex. Namespaces may be glob patterns.
+func (s *Scaler) ScaleAll(ctx context.Context, resourceType string, namespaces []string) ([]Result, error) {
+	return s.ScaleAllKinds(ctx, []string{resourceType}, namespaces)
+}
+
+// ScaleAllKinds scales all resources of several types in each of the
+// namespaces like ScaleAll, ordered together by their ordering annotations
+func (s *Scaler) ScaleAllKinds(ctx context.Context, resourceTypes []string, namespaces []string) ([]Result, error) {
+	var kinds []KindScaler
+	for _, resourceType := range resourceTypes {
+		kind, err := Lookup(resourceType)
+		if err != nil {
+			return nil, err
+		}
+		kinds = append(kinds, kind)
+	}
+
+	namespaces, err := s.ResolveNamespaces(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	var targets []Target
+	for _, ns := range namespaces {
+		for _, kind := range kinds {
+			if reason := s.namespaceExcluded(ns); reason != "" {
+				results = append(results, Result{Kind: kind.Name(), Namespace: ns, PreviousReplicas: -1, Replicas: s.opts.Replicas, Skipped: reason})
+				continue
+			}
+
+			names, err := kind.List(ctx, s.clients, ns)
+			if err != nil {
+				results = append(results, Result{Kind: kind.Name(), Namespace: ns, PreviousReplicas: -1, Replicas: s.opts.Replicas,
+					Err: fmt.Errorf("error listing %ss: %v", kind.Name(), err)})
+				continue
+			}
+
+			for _, name := range names {
+				if s.nameSelected(name) {
+					targets = append(targets, Target{Kind: kind.Name(), Namespace: ns, Name: name})
+				}
+			}
+		}
+	}
+
+	scaled, err := s.ScaleTargets(ctx, targets)
+	return append(results, scaled...), err
+}
+
+// Scale scales a single resource
+func (s *Scaler) Scale(ctx context.Context, resourceType, name, namespace string) Result {
+	return s.ScaleTo(ctx, resourceType, name, namespace, s.opts.Replicas)
+}
+
+// ScaleTo scales a single resource to the given replicas instead of Options.Replicas
+func (s *Scaler) ScaleTo(ctx context.Context, resourceType, name, namespace string, replicas int) Result {
+	if !s.opts.ResolveOwner {
+		return s.scaleTo(ctx, resourceType, name, namespace, replicas)
+	}
+
+	resolved, replaced := s.resolveOwners(ctx, []Target{{Kind: resourceType, Namespace: namespace, Name: name}})
+	result := s.scaleTo(ctx, resolved[0].Kind, resolved[0].Name, namespace, replicas)
+	result.Warnings = ownerWarnings(replaced[resolved[0]], result.Warnings)
+	return result
+}
+
+// scaleTo scales a single resource like ScaleTo, skipping resources owned by a
+// controller. Owners are resolved beforehand, by ScaleTo or ScaleTargetsTo, so
+// that a controller owning several of the resources is scaled once.
+func (s *Scaler) scaleTo(ctx context.Context, resourceType, name, namespace string, replicas int) (result Result) {
+	result = Result{Kind: resourceType, Namespace: namespace, Name: name, PreviousReplicas: -1, Replicas: replicas}
+
+	kind, err := Lookup(resourceType)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Kind = kind.Name()
+
+	// Resources owned by a controller are scaled through it, if at all
+	if controller, controllerName, ok := s.controllerOwner(ctx, kind, namespace, name); ok {
+		result.Skipped = fmt.Sprintf("owned by %s %s", controller.Name(), controllerName)
+		return result
+	}
+
+	if reason := s.excluded(namespace, name); reason != "" {
+		result.Skipped = reason
+		return result
+	}
+
+	owner, managed := s.resourceGitOpsOwner(ctx, kind, namespace, name)
+	if managed && s.opts.SkipGitOps {
+		result.Skipped = fmt.Sprintf("managed by %s", owner)
+		return result
+	}
+
+	hpas, err := s.targetingHPAs(ctx, kind, namespace, name)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("skipped HorizontalPodAutoscaler check: %v", err))
+	}
+	if len(hpas) > 0 && s.opts.HPAMode == HPASkip {
+		result.Skipped = fmt.Sprintf("targeted by HorizontalPodAutoscaler %s", hpas[0].Name)
+		return result
+	}
+
+	result.PreviousReplicas, err = kind.GetReplicas(ctx, s.clients, namespace, name)
+	if err != nil {
+		result.PreviousReplicas = -1
+		result.Err = fmt.Errorf("error getting %s: %v", kind.Name(), err)
+		return result
+	}
+
+	if s.opts.CurrentReplicas != -1 && result.PreviousReplicas != s.opts.CurrentReplicas {
+		result.Err = fmt.Errorf("current replicas %d doesn't match expected %d", result.PreviousReplicas, s.opts.CurrentReplicas)
+		return result
+	}
+
+	if s.opts.Resume {
+		return s.resume(ctx, kind, result)
+	}
+	if s.opts.Suspend {
+		return s.suspend(ctx, kind, result)
+	}
+
+	// Kinds with bounds are scaled by their bounds, with the min replicas as the replicas
+	boundsKind, hasBounds := kind.(BoundsKind)
+	var current, bounds Bounds
+	var original *Bounds
+	switch {
+	case hasBounds:
+		current, original, err = boundsKind.GetBounds(ctx, s.clients, namespace, name)
+		if err != nil {
+			result.Err = fmt.Errorf("error getting %s: %v", kind.Name(), err)
+			return result
+		}
+		if s.opts.RestoreBounds && original == nil {
+			result.Replicas = result.PreviousReplicas
+			result.Skipped = "no original bounds recorded"
+			return result
+		}
+
+		bounds, original, err = s.targetBounds(current, original, replicas)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		replicas = bounds.Min
+		result.Replicas = replicas
+
+	case replicas < 0 || s.opts.MinReplicas != "" || s.opts.MaxReplicas != "" || s.opts.RestoreBounds:
+		result.Err = fmt.Errorf("%ss have no min and max replicas", kind.Name())
+		return result
+	}
+
+	elastic, isElastic := kind.(ElasticKind)
+	completions := -1
+	if s.opts.Completions {
+		if !isElastic {
+			result.Err = fmt.Errorf("%ss have no completions", kind.Name())
+			return result
+		}
+		completions, err = elastic.GetCompletions(ctx, s.clients, namespace, name)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	var checked []string
+	scaled := false
+	switch {
+	case replicas < result.PreviousReplicas:
+		checked, err = s.checkPDBs(ctx, kind, namespace, name, result.PreviousReplicas, replicas)
+
+		// Budgets relaxed for scaling to zero are restored if the resource is not scaled
+		if s.opts.AdjustPDB && replicas == 0 && !s.opts.DryRun {
+			defer func() {
+				if result.Err == nil || scaled {
+					return
+				}
+				restored, err := s.restorePDBs(ctx, kind, namespace, name)
+				result.Warnings = append(result.Warnings, restored...)
+				if err != nil {
+					result.Warnings = append(result.Warnings, err.Error())
+				}
+			}()
+		}
+	case replicas > result.PreviousReplicas:
+		checked, err = s.checkQuota(ctx, kind, namespace, name, result.PreviousReplicas, replicas)
+	}
+	result.Warnings = append(result.Warnings, checked...)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	switch {
+	case managed && s.opts.DryRun && s.opts.SuspendGitOps:
+		result.Warnings = append(result.Warnings, fmt.Sprintf("would suspend %s", owner))
+	case managed && s.opts.DryRun && s.opts.ResumeGitOps:
+		result.Warnings = append(result.Warnings, fmt.Sprintf("would resume %s", owner))
+	case managed && !s.opts.SuspendGitOps && !s.opts.ResumeGitOps:
+		result.Warnings = append(result.Warnings, fmt.Sprintf("managed by %s, the change may be reverted", owner))
+	}
+
+	adjusted, err := s.applyHPAMode(ctx, namespace, hpas, replicas)
+	result.Warnings = append(result.Warnings, adjusted...)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	if s.opts.DryRun {
+		if hasBounds {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("would change bounds from %s to %s replicas", current, bounds))
+		}
+		if s.opts.Completions {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("would change completions from %d to %d", completions, replicas))
+		}
+		if replicas == 0 || result.PreviousReplicas == 0 {
+			handled, err := s.handleActiveJobs(ctx, kind, namespace, name, replicas == 0)
+			result.Warnings = append(result.Warnings, handled...)
+			result.Err = err
+		}
+		return result
+	}
+
+	// Nothing is reverted when the replicas do not change
+	resource := fmt.Sprintf("%s/%s/%s", kind.Name(), namespace, name)
+	if managed && s.opts.SuspendGitOps && replicas != result.PreviousReplicas {
+		suspended, err := s.suspendGitOps(ctx, owner, resource, result.PreviousReplicas)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		result.Warnings = append(result.Warnings, suspended)
+	}
+
+	err = s.apply(func(clients Clients) error {
+		switch {
+		case hasBounds:
+			return boundsKind.SetBounds(ctx, clients, namespace, name, bounds, original)
+		case s.opts.Completions:
+			return elastic.SetElastic(ctx, clients, namespace, name, replicas)
+		default:
+			return kind.SetReplicas(ctx, clients, namespace, name, replicas)
+		}
+	})
+	if err != nil {
+		if apierrors.IsConflict(err) && !s.opts.ForceConflicts {
+			result.Err = fmt.Errorf("error scaling: %v (force conflicts to take ownership of the field)", err)
+			return result
+		}
+		result.Err = fmt.Errorf("error scaling: %v", err)
+		return result
+	}
+	scaled = true
+	if hasBounds {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("changed bounds from %s to %s replicas", current, bounds))
+	}
+	if s.opts.Completions {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("changed completions from %d to %d", completions, replicas))
+	}
+
+	if replicas == 0 || result.PreviousReplicas == 0 {
+		handled, err := s.handleActiveJobs(ctx, kind, namespace, name, replicas == 0)
+		result.Warnings = append(result.Warnings, handled...)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	if replicas > 0 && result.PreviousReplicas == 0 {
+		restored, err := s.restorePDBs(ctx, kind, namespace, name)
+		result.Warnings = append(result.Warnings, restored...)
+		if err != nil {
+			result.Warnings = append(result.Warnings, err.Error())
+		}
+	}
+
+	if managed {
+		var resumed string
+		if s.opts.ResumeGitOps {
+			resumed, err = s.resumeGitOps(ctx, owner)
+		} else {
+			resumed, err = s.releaseGitOps(ctx, owner, resource, replicas)
+		}
+		if err != nil {
+			result.Warnings = append(result.Warnings, err.Error())
+		} else if resumed != "" {
+			result.Warnings = append(result.Warnings, resumed)
+		}
+	}
+	return result
+}
+
+// Namespaces returns the namespaces to operate on, falling back to the default namespace
+func (s *Scaler) Namespaces(namespaces []string) []string {
+	if len(namespaces) == 0 {
+		return []string{s.opts.DefaultNamespace}
+	}
+	return namespaces
+}
+
+// ParseNamespaces splits a comma-separated namespace list, returning nil when empty
+func ParseNamespaces(namespaces string) []string {
+	if namespaces == "" {
+		return nil
+	}
+	return strings.Split(namespaces, ",")
+}
+
+// ResourceRef is a resource of a kind given on the command line
+type ResourceRef struct {
+	Kind string
+	Name string
+}
+
+// String formats the resource as kind/name
+func (r ResourceRef) String() string {
+	return r.Kind + "/" + r.Name
+}
+
+// ParseResourceRefs parses resources given as arguments as name or kind/name.
+// A kind prefix is resolved to the registered kind, and a name without one
+// refers to a resource of each of the default kinds.
+func ParseResourceRefs(defaultKinds []string, args []string) ([]ResourceRef, error) {
+	refs := make([]ResourceRef, 0, len(args))
+	for _, arg := range args {
+		resourceType, name, found := strings.Cut(arg, "/")
+		if !found {
+			for _, resourceType := range defaultKinds {
+				kind, err := Lookup(resourceType)
+				if err != nil {
+					return nil, err
+				}
+				refs = append(refs, ResourceRef{Kind: kind.Name(), Name: arg})
+			}
+			continue
+		}
+
+		if resourceType == "" || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("invalid resource format: %s", arg)
+		}
+		kind, err := Lookup(resourceType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource %s: %v", arg, err)
+		}
+		refs = append(refs, ResourceRef{Kind: kind.Name(), Name: name})
+	}
+	return refs, nil
+}
+
+// ParseResourceNames parses resource names of a type given as arguments,
+// accepting both name and type/name. A type prefix must name the same kind.
+func ParseResourceNames(resourceType string, args []string) ([]string, error) {
+	kind, err := Lookup(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := ParseResourceRefs([]string{kind.Name()}, args)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceNames := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Kind != kind.Name() {
+			return nil, fmt.Errorf("%s is not a %s", ref, kind.Name())
+		}
+		resourceNames = append(resourceNames, ref.Name)
+	}
+	return resourceNames, nil
+}
+
+// Helper function to create int32 pointer
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+// List returns the names of all resources of the specified type in a namespace
+func (s *Scaler) List(ctx context.Context, resourceType, namespace string) ([]string, error) {
+	kind, err := Lookup(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	return kind.List(ctx, s.clients, namespace)
+}
@@ -0,0 +1,655 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestScaleResource(t *testing.T) {
+	// Create a fake clientset
+	clientset := fake.NewSimpleClientset()
+
+	// Create a test deployment
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "default",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(3),
+		},
+	}
+
+	// Add the deployment to the fake clientset
+	_, err := clientset.AppsV1().Deployments("default").Create(context.TODO(), deployment, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create test deployment: %v", err)
+	}
+
+	// Test scaling the deployment
+	scaler := NewScaler(clientset, nil, Options{Replicas: 5, CurrentReplicas: 3})
+	result := scaler.Scale(context.TODO(), "deployment", "test-deployment", "default")
+	if !result.Succeeded() {
+		t.Fatalf("Failed to scale deployment: %v", result.Err)
+	}
+
+	if result.PreviousReplicas != 3 {
+		t.Errorf("Expected previous replicas 3, got %d", result.PreviousReplicas)
+	}
+
+	// Verify the deployment was scaled
+	updatedDeployment, err := clientset.AppsV1().Deployments("default").Get(context.TODO(), "test-deployment", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get updated deployment: %v", err)
+	}
+
+	if *updatedDeployment.Spec.Replicas != 5 {
+		t.Errorf("Expected 5 replicas, got %d", *updatedDeployment.Spec.Replicas)
+	}
+}
+
+func TestScaleResourceWithInvalidCurrentReplicas(t *testing.T) {
+	// Create a fake clientset
+	clientset := fake.NewSimpleClientset()
+
+	// Create a test deployment
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-deployment",
+			Namespace: "default",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(3),
+		},
+	}
+
+	// Add the deployment to the fake clientset
+	_, err := clientset.AppsV1().Deployments("default").Create(context.TODO(), deployment, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create test deployment: %v", err)
+	}
+
+	// Test scaling with invalid current replicas
+	scaler := NewScaler(clientset, nil, Options{Replicas: 5, CurrentReplicas: 2})
+	result := scaler.Scale(context.TODO(), "deployment", "test-deployment", "default")
+	if result.Succeeded() {
+		t.Error("Expected error when current replicas don't match, got nil")
+	}
+}
+
+func TestScaleResourceWithNonExistentResource(t *testing.T) {
+	// Create a fake clientset
+	clientset := fake.NewSimpleClientset()
+
+	// Test scaling a non-existent deployment
+	scaler := NewScaler(clientset, nil, Options{Replicas: 5, CurrentReplicas: -1})
+	result := scaler.Scale(context.TODO(), "deployment", "non-existent", "default")
+	if result.Succeeded() {
+		t.Error("Expected error when scaling non-existent deployment, got nil")
+	}
+}
+
+func TestScaleResourceAcrossNamespaces(t *testing.T) {
+	// Create a fake clientset
+	clientset := fake.NewSimpleClientset()
+
+	// Define test namespaces
+	namespaces := []string{"default", "staging", "production"}
+
+	// Create each namespace first
+	for _, ns := range namespaces {
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: ns,
+			},
+		}
+		_, err := clientset.CoreV1().Namespaces().Create(context.TODO(), namespace, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Failed to create namespace %s: %v", ns, err)
+		}
+	}
+
+	// Create a deployment in each namespace
+	for _, ns := range namespaces {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-deployment",
+				Namespace: ns,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: int32Ptr(2),
+			},
+		}
+
+		// Add the deployment to the fake clientset
+		_, err := clientset.AppsV1().Deployments(ns).Create(context.TODO(), deployment, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Failed to create test deployment in namespace %s: %v", ns, err)
+		}
+	}
+
+	// Print all deployments to verify creation
+	fmt.Println("Verifying deployments were created:")
+	for _, ns := range namespaces {
+		deployments, err := clientset.AppsV1().Deployments(ns).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("Failed to list deployments in namespace %s: %v", ns, err)
+		}
+		fmt.Printf("Namespace %s has %d deployments\n", ns, len(deployments.Items))
+		for _, d := range deployments.Items {
+			fmt.Printf("  - %s: %d replicas\n", d.Name, *d.Spec.Replicas)
+		}
+	}
+
+	// Scale all deployments across namespaces using the fake clientset
+	fmt.Println("Scaling deployments across namespaces:", strings.Join(namespaces, ","))
+	scaler := NewScaler(clientset, nil, Options{Replicas: 4, CurrentReplicas: 2})
+	results, err := scaler.ScaleAll(context.TODO(), "deployment", namespaces)
+	if err != nil {
+		t.Fatalf("Failed to scale deployments across namespaces: %v", err)
+	}
+
+	if len(results) != len(namespaces) {
+		t.Fatalf("Expected %d results, got %d", len(namespaces), len(results))
+	}
+
+	for _, result := range results {
+		if !result.Succeeded() {
+			t.Errorf("Failed to scale deployment in namespace %s: %v", result.Namespace, result.Err)
+		}
+	}
+
+	// Verify that deployments in each namespace were scaled correctly
+	for _, ns := range namespaces {
+		deployment, err := clientset.AppsV1().Deployments(ns).Get(context.TODO(), "test-deployment", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment in namespace %s: %v", ns, err)
+		}
+
+		if *deployment.Spec.Replicas != 4 {
+			t.Errorf("Expected 4 replicas in namespace %s, got %d", ns, *deployment.Spec.Replicas)
+		}
+
+		if deployment.Namespace != ns {
+			t.Errorf("Expected deployment in namespace %s, got %s", ns, deployment.Namespace)
+		}
+	}
+}
+
+func TestScaleAllResourcesInDefaultNamespace(t *testing.T) {
+	// Create a fake clientset with deployments in two namespaces
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "team-a"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+	)
+
+	// Scale without namespaces, using the context namespace as the default
+	scaler := NewScaler(clientset, nil, Options{Replicas: 0, CurrentReplicas: -1, DefaultNamespace: "team-a"})
+	if _, err := scaler.ScaleAll(context.TODO(), "deployment", nil); err != nil {
+		t.Fatalf("Failed to scale deployments: %v", err)
+	}
+
+	expected := map[string]int32{"team-a": 0, "default": 2}
+	for ns, replicas := range expected {
+		deployment, err := clientset.AppsV1().Deployments(ns).Get(context.TODO(), "test-deployment", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment in namespace %s: %v", ns, err)
+		}
+
+		if *deployment.Spec.Replicas != replicas {
+			t.Errorf("Expected %d replicas in namespace %s, got %d", replicas, ns, *deployment.Spec.Replicas)
+		}
+	}
+}
+
+func TestScaleNames(t *testing.T) {
+	// Create a fake clientset with a statefulset in two namespaces
+	clientset := fake.NewSimpleClientset(
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "mysql", Namespace: "staging"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(1)},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "mysql", Namespace: "production"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+		},
+	)
+
+	scaler := NewScaler(clientset, nil, Options{Replicas: 2, CurrentReplicas: -1})
+	results, err := scaler.ScaleNames(context.TODO(), "sts", []string{"mysql", "missing"}, []string{"staging", "production"})
+	if err != nil {
+		t.Fatalf("Failed to scale statefulsets: %v", err)
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+
+	for _, result := range results {
+		if result.Kind != "statefulset" {
+			t.Errorf("Expected kind statefulset, got %s", result.Kind)
+		}
+
+		if result.Succeeded() == (result.Name == "missing") {
+			t.Errorf("Unexpected result for %s in namespace %s: %v", result.Name, result.Namespace, result.Err)
+		}
+	}
+}
+
+func TestScaleFile(t *testing.T) {
+	// Create a fake clientset with deployments in two namespaces
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(1)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "staging"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(1)},
+		},
+	)
+
+	manifest := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: staging
+`
+
+	scaler := NewScaler(clientset, nil, Options{Replicas: 3, CurrentReplicas: -1, DefaultNamespace: "team-a"})
+	results, err := scaler.ScaleFile(context.TODO(), strings.NewReader(manifest))
+	if err != nil {
+		t.Fatalf("Failed to scale from file: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	for _, result := range results {
+		if !result.Succeeded() {
+			t.Errorf("Failed to scale %s in namespace %s: %v", result.Name, result.Namespace, result.Err)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	// Create a fake clientset with deployments in two namespaces
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "staging"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "staging"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(1)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "production"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 3},
+		},
+	)
+
+	scaler := NewScaler(clientset, nil, Options{CurrentReplicas: -1})
+	statuses, err := scaler.Status(context.TODO(), "deploy", []string{"api"}, []string{"staging", "production"})
+	if err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 statuses, got %d", len(statuses))
+	}
+
+	expected := map[string][2]int{"staging": {2, 1}, "production": {3, 3}}
+	for _, status := range statuses {
+		if status.Name != "api" || status.Kind != "deployment" {
+			t.Errorf("Expected deployment/api, got %s/%s", status.Kind, status.Name)
+		}
+
+		if got := [2]int{status.Desired, status.Ready}; got != expected[status.Namespace] {
+			t.Errorf("Expected %v in namespace %s, got %v", expected[status.Namespace], status.Namespace, got)
+		}
+	}
+}
+
+func TestScaleAllWithExclusions(t *testing.T) {
+	// Create a fake clientset with deployments in three namespaces
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "staging"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-canary", Namespace: "staging"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+	)
+
+	scaler := NewScaler(clientset, nil, Options{
+		Replicas:          0,
+		CurrentReplicas:   -1,
+		ExcludeNamespaces: []string{"kube-*"},
+		ExcludeNames:      []string{"*-canary"},
+	})
+	results, err := scaler.ScaleAll(context.TODO(), "deployment", []string{"staging", "kube-system"})
+	if err != nil {
+		t.Fatalf("Failed to scale deployments: %v", err)
+	}
+
+	skipped := map[string]string{}
+	for _, result := range results {
+		if result.Skipped != "" {
+			skipped[result.Namespace+"/"+result.Name] = result.Skipped
+		}
+	}
+
+	expected := map[string]string{
+		"staging/api-canary": `name matches exclude pattern "*-canary"`,
+		"kube-system/":       `namespace matches exclude pattern "kube-*"`,
+	}
+	if len(skipped) != len(expected) {
+		t.Fatalf("Expected %d skipped results, got %v", len(expected), skipped)
+	}
+	for key, reason := range expected {
+		if skipped[key] != reason {
+			t.Errorf("Expected %s to be skipped with %q, got %q", key, reason, skipped[key])
+		}
+	}
+
+	expectedReplicas := map[string]int32{"staging/api": 0, "staging/api-canary": 2, "kube-system/coredns": 2}
+	for key, replicas := range expectedReplicas {
+		parts := strings.SplitN(key, "/", 2)
+		deployment, err := clientset.AppsV1().Deployments(parts[0]).Get(context.TODO(), parts[1], metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment %s: %v", key, err)
+		}
+
+		if *deployment.Spec.Replicas != replicas {
+			t.Errorf("Expected %d replicas for %s, got %d", replicas, key, *deployment.Spec.Replicas)
+		}
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	if err := (Options{ExcludeNames: []string{"api-["}}).Validate(); err == nil {
+		t.Error("Expected error for malformed pattern, got nil")
+	}
+
+	if err := (Options{ExcludeNamespaces: []string{"kube-*", "pr-[0-9]*"}}).Validate(); err != nil {
+		t.Errorf("Expected valid patterns, got %v", err)
+	}
+
+	if err := (Options{NameRegex: "^worker-("}).Validate(); err == nil {
+		t.Error("Expected error for malformed name regex, got nil")
+	}
+
+	if err := (Options{HPAMode: "ignore"}).Validate(); err == nil {
+		t.Error("Expected error for unknown HPA mode, got nil")
+	}
+
+	if err := (Options{ActiveJobs: "orphan"}).Validate(); err == nil {
+		t.Error("Expected error for unknown active jobs policy, got nil")
+	}
+
+	if err := (Options{Suspend: true, Resume: true}).Validate(); err == nil {
+		t.Error("Expected error for Suspend combined with Resume, got nil")
+	}
+
+	if err := (Options{Completions: true, Suspend: true}).Validate(); err == nil {
+		t.Error("Expected error for Completions combined with Suspend, got nil")
+	}
+}
+
+func TestScaleWithPatterns(t *testing.T) {
+	// Create a fake clientset with deployments in two team namespaces and one other
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "platform"}},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-gateway", Namespace: "team-a"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Namespace: "team-a"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-users", Namespace: "team-b"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-gateway", Namespace: "platform"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+	)
+
+	scaler := NewScaler(clientset, nil, Options{Replicas: 0, CurrentReplicas: -1})
+	results, err := scaler.ScaleNames(context.TODO(), "deployment", []string{"api-*"}, []string{"team-*"})
+	if err != nil {
+		t.Fatalf("Failed to scale deployments: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	scaler = NewScaler(clientset, nil, Options{Replicas: 1, CurrentReplicas: -1, NameRegex: "^worker-"})
+	results, err = scaler.ScaleAll(context.TODO(), "deployment", []string{"team-*"})
+	if err != nil {
+		t.Fatalf("Failed to scale deployments: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "worker-1" {
+		t.Fatalf("Expected only worker-1 to be scaled, got %v", results)
+	}
+
+	expectedReplicas := map[string]int32{
+		"team-a/api-gateway":   0,
+		"team-a/worker-1":      1,
+		"team-b/api-users":     0,
+		"platform/api-gateway": 2,
+	}
+	for key, replicas := range expectedReplicas {
+		parts := strings.SplitN(key, "/", 2)
+		deployment, err := clientset.AppsV1().Deployments(parts[0]).Get(context.TODO(), parts[1], metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment %s: %v", key, err)
+		}
+
+		if *deployment.Spec.Replicas != replicas {
+			t.Errorf("Expected %d replicas for %s, got %d", replicas, key, *deployment.Spec.Replicas)
+		}
+	}
+}
+
+func TestScaleServerSideApply(t *testing.T) {
+	clientset := fake.NewClientset()
+
+	// Another field manager, such as a GitOps controller, owns spec.replicas
+	deployment := appsv1ac.Deployment("api", "default").
+		WithSpec(appsv1ac.DeploymentSpec().WithReplicas(3))
+	if _, err := clientset.AppsV1().Deployments("default").Apply(context.TODO(), deployment, metav1.ApplyOptions{FieldManager: "gitops"}); err != nil {
+		t.Fatalf("Failed to apply deployment: %v", err)
+	}
+
+	result := NewScaler(clientset, nil, Options{Replicas: 1, CurrentReplicas: -1}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err == nil {
+		t.Fatal("Expected a conflict with the other field manager, got nil")
+	}
+
+	result = NewScaler(clientset, nil, Options{Replicas: 1, CurrentReplicas: -1, ForceConflicts: true}).Scale(context.TODO(), "deployment", "api", "default")
+	if result.Err != nil {
+		t.Fatalf("Expected forcing conflicts to succeed, got %v", result.Err)
+	}
+
+	updated, err := clientset.AppsV1().Deployments("default").Get(context.TODO(), "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if *updated.Spec.Replicas != 1 {
+		t.Errorf("Expected 1 replica, got %d", *updated.Spec.Replicas)
+	}
+
+	managers := map[string]bool{}
+	for _, entry := range updated.ManagedFields {
+		managers[entry.Manager] = true
+	}
+	if !managers[FieldManager] {
+		t.Errorf("Expected %s in managedFields, got %v", FieldManager, updated.ManagedFields)
+	}
+}
+
+func TestScaleTakesOverUpdatedFields(t *testing.T) {
+	// kubectl create, edit and client-side apply own fields with an update
+	clientset := newManagedClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+		},
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-hpa", Namespace: "default"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "worker"},
+				MinReplicas:    int32Ptr(2),
+				MaxReplicas:    4,
+			},
+		},
+	)
+
+	for kind, replicas := range map[string]int{"deployment": 0, "statefulset": 0, "hpa": 1} {
+		scaler := NewScaler(clientset, nil, Options{Replicas: replicas, CurrentReplicas: -1})
+		results, err := scaler.ScaleAll(context.TODO(), kind, []string{"default"})
+		if err != nil {
+			t.Fatalf("Failed to scale %ss: %v", kind, err)
+		}
+		if len(results) != 1 || !results[0].Succeeded() {
+			t.Fatalf("Expected the %s to be scaled without forcing conflicts, got %+v", kind, results)
+		}
+	}
+
+	deployment, err := clientset.AppsV1().Deployments("default").Get(context.TODO(), "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if *deployment.Spec.Replicas != 0 {
+		t.Errorf("Expected 0 replicas, got %d", *deployment.Spec.Replicas)
+	}
+	statefulset, err := clientset.AppsV1().StatefulSets("default").Get(context.TODO(), "db", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get statefulset: %v", err)
+	}
+	if *statefulset.Spec.Replicas != 0 {
+		t.Errorf("Expected 0 replicas, got %d", *statefulset.Spec.Replicas)
+	}
+	hpa, err := clientset.AutoscalingV2().HorizontalPodAutoscalers("default").Get(context.TODO(), "worker-hpa", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get HPA: %v", err)
+	}
+	if *hpa.Spec.MinReplicas != 1 {
+		t.Errorf("Expected min replicas 1, got %d", *hpa.Spec.MinReplicas)
+	}
+}
+
+func TestParseResourceRefs(t *testing.T) {
+	refs, err := ParseResourceRefs([]string{"deploy", "sts"}, []string{"api", "statefulset/db", "cj/backup"})
+	if err != nil {
+		t.Fatalf("Failed to parse resources: %v", err)
+	}
+
+	expected := []ResourceRef{
+		{Kind: "deployment", Name: "api"},
+		{Kind: "statefulset", Name: "api"},
+		{Kind: "statefulset", Name: "db"},
+		{Kind: "cronjob", Name: "backup"},
+	}
+	if fmt.Sprint(refs) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, refs)
+	}
+
+	for _, arg := range []string{"unknown/api", "deployment/", "deployment/api/extra"} {
+		if _, err := ParseResourceRefs([]string{"deployment"}, []string{arg}); err == nil {
+			t.Errorf("Expected error parsing %q, got nil", arg)
+		}
+	}
+
+	if _, err := ParseResourceNames("deployment", []string{"statefulset/db"}); err == nil {
+		t.Error("Expected error for a name of another kind, got nil")
+	}
+	if names, err := ParseResourceNames("deploy", []string{"deployment/api", "web"}); err != nil || fmt.Sprint(names) != "[api web]" {
+		t.Errorf("Expected [api web], got %v: %v", names, err)
+	}
+}
+
+func TestScaleMultipleKinds(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "staging"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "staging"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(1)},
+		},
+	)
+	scaler := NewScaler(clientset, nil, Options{Replicas: 0, CurrentReplicas: -1})
+
+	results, err := scaler.ScaleRefs(context.TODO(), []ResourceRef{{Kind: "deploy", Name: "api"}, {Kind: "sts", Name: "db"}}, []string{"staging"})
+	if err != nil {
+		t.Fatalf("Failed to scale resources: %v", err)
+	}
+	if len(results) != 2 || !results[0].Succeeded() || !results[1].Succeeded() {
+		t.Fatalf("Expected 2 scaled resources, got %+v", results)
+	}
+
+	statefulset, err := clientset.AppsV1().StatefulSets("staging").Get(context.TODO(), "db", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get statefulset: %v", err)
+	}
+	if *statefulset.Spec.Replicas != 0 {
+		t.Errorf("Expected statefulset to be scaled to 0, got %d", *statefulset.Spec.Replicas)
+	}
+
+	scaler = NewScaler(clientset, nil, Options{Replicas: 3, CurrentReplicas: -1})
+	results, err = scaler.ScaleAllKinds(context.TODO(), []string{"deployment", "statefulset"}, []string{"staging"})
+	if err != nil {
+		t.Fatalf("Failed to scale all resources: %v", err)
+	}
+	kinds := map[string]int{}
+	for _, result := range results {
+		if result.Succeeded() && result.Replicas == 3 {
+			kinds[result.Kind]++
+		}
+	}
+	if kinds["deployment"] != 1 || kinds["statefulset"] != 1 {
+		t.Errorf("Expected a deployment and a statefulset to be scaled to 3, got %+v", results)
+	}
+}
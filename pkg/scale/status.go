@@ -0,0 +1,64 @@
+package scale
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkloadStatus is the replica status of a resource in a namespace
+type WorkloadStatus struct {
+	Kind      string
+	Namespace string
+	ReplicaStatus
+}
+
+// Status returns the desired and ready replicas of all resources of a type in
+// each of the namespaces, restricted to the given names or glob patterns when
+// any are given and to Options.NameRegex. Namespaces may be glob patterns.
+func (s *Scaler) Status(ctx context.Context, resourceType string, names, namespaces []string) ([]WorkloadStatus, error) {
+	kind, err := Lookup(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, err = s.ResolveNamespaces(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []WorkloadStatus
+	for _, ns := range namespaces {
+		replicaStatuses, err := kind.Status(ctx, s.clients, ns)
+		if err != nil {
+			return nil, fmt.Errorf("error listing %ss in namespace %s: %v", kind.Name(), ns, err)
+		}
+
+		for _, status := range replicaStatuses {
+			if _, ok := matchingPattern(names, status.Name); len(names) > 0 && !ok {
+				continue
+			}
+			if !s.nameSelected(status.Name) {
+				continue
+			}
+			statuses = append(statuses, WorkloadStatus{Kind: kind.Name(), Namespace: ns, ReplicaStatus: status})
+		}
+	}
+
+	return statuses, nil
+}
+
+// ListNamespaces returns the names of all namespaces in the cluster
+func (s *Scaler) ListNamespaces(ctx context.Context) ([]string, error) {
+	namespaces, err := s.clients.Kubernetes.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing namespaces: %v", err)
+	}
+
+	var names []string
+	for _, namespace := range namespaces.Items {
+		names = append(names, namespace.Name)
+	}
+	return names, nil
+}
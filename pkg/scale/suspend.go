@@ -0,0 +1,193 @@
+package scale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	batchv1ac "k8s.io/client-go/applyconfigurations/batch/v1"
+)
+
+// ActiveJobsPolicy is what happens to the running Jobs of a CronJob when it is
+// suspended or scaled to zero
+type ActiveJobsPolicy string
+
+const (
+	// ActiveJobsKeep lets running Jobs finish
+	ActiveJobsKeep ActiveJobsPolicy = "keep"
+	// ActiveJobsDelete deletes running Jobs and their pods
+	ActiveJobsDelete ActiveJobsPolicy = "delete"
+	// ActiveJobsSuspend suspends running Jobs, marking them with
+	// SuspendedJobAnnotation, so that they are resumed along with the CronJob
+	ActiveJobsSuspend ActiveJobsPolicy = "suspend"
+)
+
+// SuspendedJobAnnotation marks a running Job suspended by ActiveJobsSuspend. Such
+// Jobs are resumed whenever their owner is resumed or scaled up again, whatever
+// the ActiveJobs policy, while Jobs suspended by others are left alone.
+const SuspendedJobAnnotation = "mscale.io/suspended"
+
+// ActiveJobsPolicies are the valid values of Options.ActiveJobs
+var ActiveJobsPolicies = []ActiveJobsPolicy{ActiveJobsKeep, ActiveJobsDelete, ActiveJobsSuspend}
+
+// resume hands a resource of a resumable kind back to its autoscaler, or resumes
+// a suspended resource
+func (s *Scaler) resume(ctx context.Context, kind KindScaler, result Result) Result {
+	result.Replicas = result.PreviousReplicas
+	resumable, ok := kind.(ResumableKind)
+	if !ok {
+		result.Err = fmt.Errorf("%ss cannot be resumed", kind.Name())
+		return result
+	}
+
+	result.Resumed = true
+	if !s.opts.DryRun {
+		err := s.apply(func(clients Clients) error {
+			return resumable.Resume(ctx, clients, result.Namespace, result.Name)
+		})
+		if err != nil {
+			result.Err = fmt.Errorf("error resuming: %v", err)
+			return result
+		}
+		if replicas, err := kind.GetReplicas(ctx, s.clients, result.Namespace, result.Name); err == nil {
+			result.Replicas = replicas
+		}
+	}
+
+	handled, err := s.handleActiveJobs(ctx, kind, result.Namespace, result.Name, false)
+	result.Warnings = append(result.Warnings, handled...)
+	result.Err = err
+	return result
+}
+
+// suspend suspends a resource of a suspendable kind without changing its replicas
+func (s *Scaler) suspend(ctx context.Context, kind KindScaler, result Result) Result {
+	result.Replicas = result.PreviousReplicas
+	suspendable, ok := kind.(SuspendableKind)
+	if !ok {
+		result.Err = fmt.Errorf("%ss cannot be suspended", kind.Name())
+		return result
+	}
+
+	result.Suspended = true
+	if !s.opts.DryRun {
+		err := s.apply(func(clients Clients) error {
+			return suspendable.Suspend(ctx, clients, result.Namespace, result.Name)
+		})
+		if err != nil {
+			result.Err = fmt.Errorf("error suspending: %v", err)
+			return result
+		}
+	}
+
+	handled, err := s.handleActiveJobs(ctx, kind, result.Namespace, result.Name, true)
+	result.Warnings = append(result.Warnings, handled...)
+	result.Err = err
+	return result
+}
+
+// handleActiveJobs applies the ActiveJobs policy to the running Jobs of a kind
+// that spawns Jobs. When stopping, the Jobs are deleted or suspended, otherwise
+// the Jobs marked with SuspendedJobAnnotation are resumed. It returns a warning
+// per Job.
+func (s *Scaler) handleActiveJobs(ctx context.Context, kind KindScaler, namespace, name string, stopping bool) ([]string, error) {
+	owner, ok := kind.(JobOwnerKind)
+	if !ok {
+		return nil, nil
+	}
+
+	action := "resume"
+	switch {
+	case !stopping:
+	case s.opts.ActiveJobs == ActiveJobsDelete:
+		action = "delete"
+	case s.opts.ActiveJobs == ActiveJobsSuspend:
+		action = "suspend"
+	default:
+		return nil, nil
+	}
+
+	jobs, err := owner.ActiveJobs(ctx, s.clients, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("error getting active jobs: %v", err)
+	}
+
+	var warnings []string
+	for _, job := range jobs {
+		// Only Jobs suspended by mscale are resumed, and Jobs already suspended
+		// by others are not marked as such
+		if action != "delete" {
+			current, err := s.clients.Kubernetes.BatchV1().Jobs(namespace).Get(ctx, job, metav1.GetOptions{})
+			if err != nil {
+				return warnings, fmt.Errorf("error getting active Job %s: %v", job, err)
+			}
+			_, marked := current.Annotations[SuspendedJobAnnotation]
+			suspended := current.Spec.Suspend != nil && *current.Spec.Suspend
+			if action == "resume" && !marked || action == "suspend" && suspended {
+				continue
+			}
+		}
+
+		if s.opts.DryRun {
+			warnings = append(warnings, fmt.Sprintf("would %s active Job %s", action, job))
+			continue
+		}
+
+		switch action {
+		case "delete":
+			propagation := metav1.DeletePropagationBackground
+			err = s.clients.Kubernetes.BatchV1().Jobs(namespace).Delete(ctx, job, metav1.DeleteOptions{PropagationPolicy: &propagation})
+		default:
+			err = patchActiveJobSuspend(ctx, s.clients, namespace, job, stopping)
+		}
+		if err != nil {
+			return warnings, fmt.Errorf("error trying to %s active Job %s: %v", action, job, err)
+		}
+		warnings = append(warnings, fmt.Sprintf("%sd active Job %s", action, job))
+	}
+	return warnings, nil
+}
+
+// patchActiveJobSuspend suspends a running Job and marks it with
+// SuspendedJobAnnotation, or resumes it and removes the mark
+func patchActiveJobSuspend(ctx context.Context, clients Clients, namespace, name string, suspend bool) error {
+	var mark any
+	if suspend {
+		mark = "true"
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{"annotations": map[string]any{SuspendedJobAnnotation: mark}},
+		"spec":     map[string]any{"suspend": suspend},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = clients.Kubernetes.BatchV1().Jobs(namespace).Patch(ctx, name, types.MergePatchType, patch,
+		metav1.PatchOptions{FieldManager: FieldManager})
+	return err
+}
+
+// applyJobSuspend sets spec.suspend of a Job. The current parallelism is applied
+// along with it, so that it is kept if the field manager owns it.
+func applyJobSuspend(ctx context.Context, clients Clients, namespace, name string, suspend bool) error {
+	current, err := clients.Kubernetes.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	spec := batchv1ac.JobSpec().WithSuspend(suspend)
+	if current.Spec.Parallelism != nil {
+		spec.WithParallelism(*current.Spec.Parallelism)
+	}
+	if current.Spec.CompletionMode != nil && *current.Spec.CompletionMode == batchv1.IndexedCompletion && current.Spec.Completions != nil {
+		spec.WithCompletions(*current.Spec.Completions)
+	}
+
+	_, err = clients.Kubernetes.BatchV1().Jobs(namespace).Apply(ctx, batchv1ac.Job(name, namespace).WithSpec(spec), clients.ApplyOptions())
+	return err
+}
@@ -0,0 +1,279 @@
+package scale
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newCronJobClientset returns a fake clientset with a backup cronjob of
+// parallelism 2 running the backup-1 job
+func newCronJobClientset() *fake.Clientset {
+	return fake.NewSimpleClientset(
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default"},
+			Spec: batchv1.CronJobSpec{
+				Schedule:    "0 * * * *",
+				JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Parallelism: int32Ptr(2)}},
+			},
+			Status: batchv1.CronJobStatus{Active: []corev1.ObjectReference{{Name: "backup-1", Namespace: "default"}}},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup-1", Namespace: "default"},
+			Spec:       batchv1.JobSpec{Parallelism: int32Ptr(2)},
+		},
+	)
+}
+
+func getCronJob(t *testing.T, clientset *fake.Clientset) *batchv1.CronJob {
+	t.Helper()
+	cronjob, err := clientset.BatchV1().CronJobs("default").Get(context.TODO(), "backup", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get cronjob: %v", err)
+	}
+	return cronjob
+}
+
+func TestScaleCronJobToZeroSuspends(t *testing.T) {
+	clientset := newCronJobClientset()
+
+	result := NewScaler(clientset, nil, Options{Replicas: 0, CurrentReplicas: -1}).Scale(context.TODO(), "cronjob", "backup", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to scale cronjob: %v", result.Err)
+	}
+	if result.PreviousReplicas != 2 || result.Replicas != 0 {
+		t.Errorf("Expected cronjob to be scaled from 2 to 0, got %d to %d", result.PreviousReplicas, result.Replicas)
+	}
+
+	cronjob := getCronJob(t, clientset)
+	if cronjob.Spec.Suspend == nil || !*cronjob.Spec.Suspend {
+		t.Error("Expected cronjob to be suspended")
+	}
+	if *cronjob.Spec.JobTemplate.Spec.Parallelism != 2 {
+		t.Errorf("Expected parallelism to be kept, got %d", *cronjob.Spec.JobTemplate.Spec.Parallelism)
+	}
+	if _, err := clientset.BatchV1().Jobs("default").Get(context.TODO(), "backup-1", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected active job to be kept, got %v", err)
+	}
+
+	result = NewScaler(clientset, nil, Options{Replicas: 3, CurrentReplicas: 0}).Scale(context.TODO(), "cronjob", "backup", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to scale cronjob up: %v", result.Err)
+	}
+
+	cronjob = getCronJob(t, clientset)
+	if cronjob.Spec.Suspend == nil || *cronjob.Spec.Suspend {
+		t.Error("Expected cronjob to be resumed")
+	}
+	if *cronjob.Spec.JobTemplate.Spec.Parallelism != 3 {
+		t.Errorf("Expected parallelism 3, got %d", *cronjob.Spec.JobTemplate.Spec.Parallelism)
+	}
+}
+
+func TestSuspendCronJobDeletesActiveJobs(t *testing.T) {
+	clientset := newCronJobClientset()
+
+	result := NewScaler(clientset, nil, Options{Replicas: -1, CurrentReplicas: -1, Suspend: true, ActiveJobs: ActiveJobsDelete}).Scale(context.TODO(), "cj", "backup", "default")
+	if result.Err != nil || !result.Suspended {
+		t.Fatalf("Failed to suspend cronjob: %+v", result)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Expected a warning for the deleted job, got %v", result.Warnings)
+	}
+
+	if cronjob := getCronJob(t, clientset); cronjob.Spec.Suspend == nil || !*cronjob.Spec.Suspend {
+		t.Error("Expected cronjob to be suspended")
+	}
+	if _, err := clientset.BatchV1().Jobs("default").Get(context.TODO(), "backup-1", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected active job to be deleted, got %v", err)
+	}
+}
+
+func TestSuspendAndResumeCronJobActiveJobs(t *testing.T) {
+	clientset := newCronJobClientset()
+	ctx := context.TODO()
+
+	result := NewScaler(clientset, nil, Options{Replicas: -1, CurrentReplicas: -1, Suspend: true, ActiveJobs: ActiveJobsSuspend}).Scale(ctx, "cronjob", "backup", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to suspend cronjob: %v", result.Err)
+	}
+	job, err := clientset.BatchV1().Jobs("default").Get(ctx, "backup-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if job.Spec.Suspend == nil || !*job.Spec.Suspend {
+		t.Error("Expected active job to be suspended")
+	}
+
+	result = NewScaler(clientset, nil, Options{Replicas: -1, CurrentReplicas: -1, Resume: true, ActiveJobs: ActiveJobsSuspend}).Scale(ctx, "cronjob", "backup", "default")
+	if result.Err != nil || !result.Resumed {
+		t.Fatalf("Failed to resume cronjob: %+v", result)
+	}
+	if result.PreviousReplicas != 0 || result.Replicas != 2 {
+		t.Errorf("Expected cronjob to be resumed from 0 at 2 replicas, got %d to %d", result.PreviousReplicas, result.Replicas)
+	}
+	job, err = clientset.BatchV1().Jobs("default").Get(ctx, "backup-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if job.Spec.Suspend == nil || *job.Spec.Suspend {
+		t.Error("Expected active job to be resumed")
+	}
+}
+
+func TestResumeCronJobOnlyResumesMarkedJobs(t *testing.T) {
+	clientset := newCronJobClientset()
+	ctx := context.TODO()
+
+	// backup-2 was suspended by someone else
+	cronjob := getCronJob(t, clientset)
+	cronjob.Status.Active = append(cronjob.Status.Active, corev1.ObjectReference{Name: "backup-2", Namespace: "default"})
+	if _, err := clientset.BatchV1().CronJobs("default").Update(ctx, cronjob, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update cronjob: %v", err)
+	}
+	suspend := true
+	other := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-2", Namespace: "default"},
+		Spec:       batchv1.JobSpec{Suspend: &suspend},
+	}
+	if _, err := clientset.BatchV1().Jobs("default").Create(ctx, other, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create job: %v", err)
+	}
+
+	result := NewScaler(clientset, nil, Options{Replicas: 0, CurrentReplicas: -1, ActiveJobs: ActiveJobsSuspend}).Scale(ctx, "cronjob", "backup", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to scale cronjob: %v", result.Err)
+	}
+
+	// Resuming with the default policy resumes the Jobs mscale suspended
+	result = NewScaler(clientset, nil, Options{Replicas: -1, CurrentReplicas: -1, Resume: true}).Scale(ctx, "cronjob", "backup", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to resume cronjob: %v", result.Err)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0] != "resumed active Job backup-1" {
+		t.Errorf("Expected only backup-1 to be resumed, got %v", result.Warnings)
+	}
+
+	for name, suspended := range map[string]bool{"backup-1": false, "backup-2": true} {
+		job, err := clientset.BatchV1().Jobs("default").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get job: %v", err)
+		}
+		if job.Spec.Suspend == nil || *job.Spec.Suspend != suspended {
+			t.Errorf("Expected %s suspended to be %t, got %v", name, suspended, job.Spec.Suspend)
+		}
+		if _, ok := job.Annotations[SuspendedJobAnnotation]; ok {
+			t.Errorf("Expected %s not to be marked as suspended by mscale", name)
+		}
+	}
+}
+
+func TestSuspendDryRun(t *testing.T) {
+	clientset := newCronJobClientset()
+
+	result := NewScaler(clientset, nil, Options{Replicas: -1, CurrentReplicas: -1, Suspend: true, ActiveJobs: ActiveJobsDelete, DryRun: true}).Scale(context.TODO(), "cronjob", "backup", "default")
+	if result.Err != nil || !result.Suspended {
+		t.Fatalf("Failed to suspend cronjob: %+v", result)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0] != "would delete active Job backup-1" {
+		t.Errorf("Expected a would delete warning, got %v", result.Warnings)
+	}
+	if cronjob := getCronJob(t, clientset); cronjob.Spec.Suspend != nil {
+		t.Error("Expected cronjob to be left alone in a dry run")
+	}
+}
+
+func TestSuspendUnsupportedKind(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+	})
+
+	result := NewScaler(clientset, nil, Options{Replicas: -1, CurrentReplicas: -1, Suspend: true}).Scale(context.TODO(), "deployment", "web", "default")
+	if result.Err == nil {
+		t.Error("Expected error suspending a deployment, got nil")
+	}
+}
+
+// newJobClientset returns a fake clientset with a NonIndexed report job and an
+// Indexed shard job, both with parallelism and completions 4
+func newJobClientset() *fake.Clientset {
+	indexed := batchv1.IndexedCompletion
+	nonIndexed := batchv1.NonIndexedCompletion
+	return fake.NewSimpleClientset(
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default"},
+			Spec:       batchv1.JobSpec{Parallelism: int32Ptr(4), Completions: int32Ptr(4), CompletionMode: &nonIndexed},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "shard", Namespace: "default"},
+			Spec:       batchv1.JobSpec{Parallelism: int32Ptr(4), Completions: int32Ptr(4), CompletionMode: &indexed},
+		},
+	)
+}
+
+func TestSuspendAndResumeJob(t *testing.T) {
+	clientset := newJobClientset()
+	ctx := context.TODO()
+
+	for _, opts := range []Options{{Suspend: true}, {Resume: true}} {
+		opts.Replicas, opts.CurrentReplicas = -1, -1
+		result := NewScaler(clientset, nil, opts).Scale(ctx, "job", "report", "default")
+		if result.Err != nil {
+			t.Fatalf("Failed to suspend or resume job: %v", result.Err)
+		}
+
+		job, err := clientset.BatchV1().Jobs("default").Get(ctx, "report", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get job: %v", err)
+		}
+		if job.Spec.Suspend == nil || *job.Spec.Suspend != opts.Suspend {
+			t.Errorf("Expected job suspend to be %t, got %v", opts.Suspend, job.Spec.Suspend)
+		}
+		if *job.Spec.Parallelism != 4 {
+			t.Errorf("Expected parallelism to be kept, got %d", *job.Spec.Parallelism)
+		}
+	}
+}
+
+func TestScaleIndexedJobCompletions(t *testing.T) {
+	clientset := newJobClientset()
+	ctx := context.TODO()
+
+	result := NewScaler(clientset, nil, Options{Replicas: 6, CurrentReplicas: -1, Completions: true}).Scale(ctx, "job", "shard", "default")
+	if result.Err != nil {
+		t.Fatalf("Failed to scale indexed job: %v", result.Err)
+	}
+
+	job, err := clientset.BatchV1().Jobs("default").Get(ctx, "shard", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if *job.Spec.Parallelism != 6 || *job.Spec.Completions != 6 {
+		t.Errorf("Expected parallelism and completions 6, got %d and %d", *job.Spec.Parallelism, *job.Spec.Completions)
+	}
+
+	result = NewScaler(clientset, nil, Options{Replicas: 6, CurrentReplicas: -1, Completions: true}).Scale(ctx, "job", "report", "default")
+	if result.Err == nil {
+		t.Error("Expected error changing the completions of a NonIndexed job, got nil")
+	}
+	if job, _ := clientset.BatchV1().Jobs("default").Get(ctx, "report", metav1.GetOptions{}); *job.Spec.Parallelism != 4 {
+		t.Errorf("Expected rejected job to be left alone, got parallelism %d", *job.Spec.Parallelism)
+	}
+
+	clientset = fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+	})
+	result = NewScaler(clientset, nil, Options{Replicas: 3, CurrentReplicas: -1, Completions: true}).Scale(ctx, "deployment", "web", "default")
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "have no completions") {
+		t.Error("Expected error changing the completions of a deployment, got nil")
+	}
+}